@@ -0,0 +1,33 @@
+package slogtesting
+
+// Assert runs each check on subject and reports each error with tb.Errorf, so
+// the test continues. The output is true if every check passes. A check may be
+// a [Check], [RecordCheck], [SequenceCheck], [EnvelopeCheck], or any other
+// func testing a value of type T.
+//
+// Failures are attributed to the line calling Assert.
+func Assert[T any](tb TB, subject T, checks ...func(T) error) bool {
+	tb.Helper()
+
+	ok := true
+	for i, check := range checks {
+		if err := check(subject); err != nil {
+			tb.Errorf(logPrefix+"check %d failed: %v", i, err)
+			ok = false
+		}
+	}
+	return ok
+}
+
+// Require is like [Assert], but it stops at the first failing check and
+// reports it with tb.Fatalf, which ends the test.
+func Require[T any](tb TB, subject T, checks ...func(T) error) {
+	tb.Helper()
+
+	for i, check := range checks {
+		if err := check(subject); err != nil {
+			tb.Fatalf(logPrefix+"check %d failed: %v", i, err)
+			return
+		}
+	}
+}
@@ -0,0 +1,103 @@
+package slogtesting_test
+
+import (
+	"log/slog"
+	"slices"
+	"strings"
+	"testing"
+
+	st "github.com/rafaelespinoza/slogtesting"
+)
+
+func TestAssert(t *testing.T) {
+	rec := st.NewRecorder(nil)
+	logInfo(rec.Handler(), "msg", "a", "b")
+	records := rec.Records()
+	requireResultLen(t, records, 1)
+
+	t.Run("pass", func(t *testing.T) {
+		st.Assert(t, st.GetRecordAttrs(records[0]), st.HasKey("a"), st.HasAttr(slog.String("a", "b")))
+		st.Assert(t, records[0], st.LevelIs(slog.LevelInfo), st.MessageIs("msg"))
+		st.Require(t, records, st.ExactlyN(nil, 1), st.NoRecord(st.LevelAtLeast(slog.LevelWarn)))
+	})
+
+	t.Run("Assert reports each failure", func(t *testing.T) {
+		var fake st.FakeTB
+		var ok, reachedEnd bool
+		passed := fake.Run(func(tb st.TB) {
+			ok = st.Assert(tb, records[0], st.MessageIs("other"), st.LevelIs(slog.LevelInfo), st.LevelIs(slog.LevelWarn))
+			reachedEnd = true
+		})
+		if ok || passed {
+			t.Error("expected a failure")
+		}
+		if !reachedEnd {
+			t.Error("expected Assert to let the test continue")
+		}
+
+		errs := fake.Errors()
+		if len(errs) != 2 {
+			t.Fatalf("wrong number of calls to Errorf; got %d, expected %d", len(errs), 2)
+		}
+		for i, want := range []string{"check 0 failed: wrong message", "check 2 failed: wrong level"} {
+			if !strings.Contains(errs[i], want) {
+				t.Errorf("expected errs[%d] (%q) to contain %q", i, errs[i], want)
+			}
+		}
+	})
+
+	t.Run("Require stops at first failure", func(t *testing.T) {
+		var fake st.FakeTB
+		var reachedEnd bool
+		passed := fake.Run(func(tb st.TB) {
+			st.Require(tb, records, st.ExactlyN(nil, 2), st.NoRecord(nil))
+			reachedEnd = true
+		})
+		if passed {
+			t.Error("expected a failure")
+		}
+		if reachedEnd {
+			t.Error("expected Require to stop the test")
+		}
+
+		fatals := fake.Fatals()
+		if len(fatals) != 1 {
+			t.Fatalf("wrong number of calls to Fatalf; got %d, expected %d", len(fatals), 1)
+		}
+		if want := "check 0 failed: got 1 records"; !strings.Contains(fatals[0], want) {
+			t.Errorf("expected %q to contain %q", fatals[0], want)
+		}
+		t.Log(fatals[0])
+	})
+}
+
+func TestFakeTB(t *testing.T) {
+	t.Run("cleanups run in reverse order", func(t *testing.T) {
+		var fake st.FakeTB
+		var calls []int
+		passed := fake.Run(func(tb st.TB) {
+			tb.Cleanup(func() { calls = append(calls, 1) })
+			tb.Cleanup(func() { calls = append(calls, 2) })
+			tb.Fatalf("stop")
+			tb.Cleanup(func() { calls = append(calls, 3) })
+		})
+		if passed || !fake.Failed() {
+			t.Error("expected a failure")
+		}
+		if !slices.Equal(calls, []int{2, 1}) {
+			t.Errorf("wrong cleanup calls; got %v, expected %v", calls, []int{2, 1})
+		}
+	})
+
+	t.Run("Fatalf outside of Run", func(t *testing.T) {
+		var fake st.FakeTB
+		fake.Fatalf("a %s", "b")
+		fake.Errorf("c %d", 1)
+		if got := fake.Fatals(); !slices.Equal(got, []string{"a b"}) {
+			t.Errorf("wrong Fatals; got %q", got)
+		}
+		if got := fake.Errors(); !slices.Equal(got, []string{"c 1"}) {
+			t.Errorf("wrong Errors; got %q", got)
+		}
+	})
+}
@@ -1,130 +1,295 @@
 package slogtesting
 
-import "log/slog"
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strings"
+)
 
 // attrBuilder is a state mechanism for a call to [slog.Handler.Handle].
+// Attributes are placed into a tree of nodes, rather than slog.Attr values, so
+// that groups can be added to after they're created. Use the attrs method of
+// the root node to get the results.
 type attrBuilder struct {
 	replaceAttr func(groups []string, attr slog.Attr) slog.Attr
-	attrsByPath map[string]*attrWithPath
-	results     []slog.Attr
+	duplicates  DuplicateKeyPolicy
+	// errorOnConflict makes the err method report conflicts.
+	errorOnConflict bool
+	root            attrNode
+	// spine holds the groups opened by WithGroup, outermost first. Each one is
+	// created upon placing the first attribute within it.
+	spine []*attrNode
+	// origin is where the attributes passed to buildAttr come from. The caller
+	// sets it before each phase of building a record.
+	origin Origin
+	// duplicateKeys lists the paths of duplicate keys, as dotted strings. It's
+	// only used for the DuplicateError policy.
+	duplicateKeys []string
+	// conflicts lists attributes which were dropped because of a Conflict.
+	conflicts []Conflict
+	// diagnostics, if non-nil, receives a trace of each placement decision.
+	diagnostics slog.Handler
+}
+
+// These describe the placement decisions in a diagnostics trace.
+const (
+	placementAdded        = "added"
+	placementKept         = "kept duplicate"
+	placementReplaced     = "replaced"
+	placementDropped      = "dropped"
+	placementConflict     = "conflict"
+	placementIgnored      = "ignored"
+	placementGroupCreated = "created group"
+	placementGroupMerged  = "merged group"
+)
+
+// attrNode is an attribute within an attrBuilder. For a group, only the key
+// of attr is used; its contents are the members field.
+type attrNode struct {
+	attr    slog.Attr
+	origin  Origin
+	group   bool
+	members []*attrNode
+	// dropped is for a group which could not be placed, so neither can
+	// anything within it. If it was dropped because of a Conflict, then
+	// conflictsWith is the path of the attribute in the way.
+	dropped       bool
+	conflictsWith []string
 }
 
 // buildAttr follows the rules stated for [slog.Handler.Handle] that are not
-// specific to builtins, and if allowed, constructs an attribute and appends it
-// to the results field. As results are updated, so is the attrsByPath field.
-// These tandem updates assist in finding, creating and updating attributes
-// arranged in groups.
+// specific to builtins, and if allowed, places an attribute within the groups
+// opened so far. Those are named by groups.
 func (ab *attrBuilder) buildAttr(groups []string, attr slog.Attr) {
+	ab.placeAttr(ab.openGroups(groups), groups, attr, ab.origin)
+}
+
+// openGroups returns the innermost of the groups opened by WithGroup, creating
+// them as needed.
+func (ab *attrBuilder) openGroups(groups []string) *attrNode {
+	node := &ab.root
+	for i, name := range groups {
+		if i == len(ab.spine) {
+			ab.spine = append(ab.spine, ab.groupWithin(node, groups[:i], name, ab.origin))
+		}
+		node = ab.spine[i]
+	}
+	return node
+}
+
+func (ab *attrBuilder) placeAttr(parent *attrNode, groups []string, attr slog.Attr, origin Origin) {
 	// From slog handler docs:
 	// 	Attr's values should be resolved.
 	// This also happens in some other places.
 	attr.Value = attr.Value.Resolve()
 	if rep := ab.replaceAttr; rep != nil && attr.Value.Kind() != slog.KindGroup {
-		attr = rep(groups, attr)
+		replaced := rep(groups, attr)
 		// Resolve again in case replaceAttr returns an unresolved attribute.
-		attr.Value = attr.Value.Resolve()
+		replaced.Value = replaced.Value.Resolve()
+		if !replaced.Equal(attr) {
+			origin = OriginReplaceAttr
+		}
+		attr = replaced
 	}
 
 	// From slog handler docs:
 	// 	If an Attr's key and value are both the zero value, ignore the Attr.
 	if attr.Equal(slog.Attr{}) {
+		ab.trace(placementIgnored, groups, attr, origin, nil)
 		return
 	}
 
-	if attr.Value.Kind() == slog.KindGroup {
-		groupAttrs := attr.Value.Group()
-		if len(groupAttrs) < 1 {
-			// From slog handler docs:
-			// 	If a group has no Attrs (even if it has a non-empty key), ignore it.
-			return
-		}
+	if attr.Value.Kind() != slog.KindGroup {
+		ab.addAttr(parent, groups, &attrNode{attr: attr, origin: origin})
+		return
+	}
 
-		if attr.Key != "" {
-			// Ensure group attributes are properly placed.
-			groups = append(groups, attr.Key)
-		}
+	groupAttrs := attr.Value.Group()
+	if len(groupAttrs) < 1 {
+		// From slog handler docs:
+		// 	If a group has no Attrs (even if it has a non-empty key), ignore it.
+		ab.trace(placementIgnored, groups, attr, origin, nil)
+		return
+	}
 
-		for i, a := range groupAttrs {
-			ab.buildAttr(groups, a)
-			// Resolve each group attribute. Though this attribute was passed to
-			// buildAttr, which calls Resolve, its resolution wouldn't be observed
-			// here because it's passed as a value.
-			groupAttrs[i].Value = a.Value.Resolve()
-		}
+	// From slog handler docs:
+	// 	If a group's key is empty, inline the group's Attrs.
+	if attr.Key != "" {
+		parent = ab.groupWithin(parent, groups, attr.Key, origin)
+		groups = appendPath(groups, attr.Key)
+	}
+
+	// In case replaceAttr turned a non-group into a group, the attributes
+	// within it have the same origin.
+	for _, a := range groupAttrs {
+		ab.placeAttr(parent, groups, a, origin)
+	}
+}
+
+// groupWithin finds or creates a group with the key within parent, depending
+// on the duplicate key policy. If the group cannot be placed, then the output
+// is a dropped group that is not attached to parent.
+func (ab *attrBuilder) groupWithin(parent *attrNode, groups []string, key string, origin Origin) *attrNode {
+	if parent.dropped {
+		return parent
+	}
+
+	group := &attrNode{attr: slog.Attr{Key: key, Value: slog.GroupValue()}, group: true}
+	i := parent.lastIndex(key)
+	if i < 0 || ab.duplicates == DuplicateKeepAll {
+		ab.trace(placementGroupCreated, groups, group.attr, origin, nil)
+		parent.members = append(parent.members, group)
+		return group
+	}
+
+	ab.reportDuplicate(groups, key)
+	existing := parent.members[i]
+	switch {
+	case existing.group:
+		ab.trace(placementGroupMerged, groups, group.attr, origin, nil)
+		return existing
+	case ab.duplicates == DuplicateLastWins:
+		ab.trace(placementReplaced, groups, group.attr, origin, nil)
+		parent.members[i] = group
+		return group
+	case ab.duplicates == DuplicateFirstWins:
+		ab.trace(placementDropped, groups, group.attr, origin, nil)
+		return &attrNode{group: true, dropped: true}
+	}
+
+	conflictsWith := appendPath(groups, key)
+	ab.trace(placementConflict, groups, group.attr, origin, conflictsWith)
+	return &attrNode{group: true, dropped: true, conflictsWith: conflictsWith}
+}
 
-		if len(groups) > 0 {
-			return
+// addAttr places a non-group attribute within parent, depending on the
+// duplicate key policy.
+func (ab *attrBuilder) addAttr(parent *attrNode, groups []string, node *attrNode) {
+	if parent.dropped {
+		if parent.conflictsWith == nil {
+			ab.trace(placementDropped, groups, node.attr, node.origin, nil)
+		} else {
+			ab.trace(placementConflict, groups, node.attr, node.origin, parent.conflictsWith)
+			ab.addConflict(groups, node, parent.conflictsWith)
 		}
+		return
+	}
 
-		attr = slog.GroupAttrs(attr.Key, groupAttrs...)
-		ab.results = append(ab.results, attr)
-		ab.attrsByPath[attr.Key] = newAttrWithPath(&attr)
+	i := parent.lastIndex(node.attr.Key)
+	if i < 0 {
+		ab.trace(placementAdded, groups, node.attr, node.origin, nil)
+		parent.members = append(parent.members, node)
+		return
+	} else if ab.duplicates == DuplicateKeepAll {
+		ab.trace(placementKept, groups, node.attr, node.origin, nil)
+		parent.members = append(parent.members, node)
 		return
 	}
 
-	if len(groups) < 1 {
-		ab.results = append(ab.results, attr)
-		ab.attrsByPath[attr.Key] = newAttrWithPath(&attr)
+	ab.reportDuplicate(groups, node.attr.Key)
+	switch ab.duplicates {
+	case DuplicateFirstWins:
+		ab.trace(placementDropped, groups, node.attr, node.origin, nil)
+		return
+	case DuplicateLastWins:
+		ab.trace(placementReplaced, groups, node.attr, node.origin, nil)
+		parent.members[i] = node
 		return
 	}
 
-	mount, path := findAttr(ab.attrsByPath, groups)
-	slog.Debug(logPrefix+"from (*ab).buildAttr, after findAttr",
-		slog.Any("input_groups", groups), slog.String("input_attr_key", attr.Key), slog.Any("input_attr_value", attr.Value),
-		slog.Bool("is_mount_nil", mount == nil), slog.Any("path", path),
-	)
-
-	if mount == nil {
-		attr = buildGroupsAroundAttr(groups, attr)
-		ab.results = append(ab.results, attr)
-
-		// Ensure that the item to map is the same item added to results. In
-		// other spots where the results field is updated in tandem with the
-		// attrsByPath map, it's enough to pass a pointer to the local variable,
-		// attr. For this case, obtain a pointer to the last item in the struct
-		// field, results, and add that to the map.
-		//
-		// As of 2025-11 it's unclear why, but the tests do not pass if a
-		// pointer to a local variable is used in the same way as the other
-		// updates to the results and attrsByPath fields. This may indicate a
-		// subtle bug, and is something to watch out for.
-		lastItem := &ab.results[len(ab.results)-1]
-		ab.attrsByPath[attr.Key] = newAttrWithPath(lastItem)
+	ab.trace(placementKept, groups, node.attr, node.origin, nil)
+	parent.members = append(parent.members, node)
+}
+
+// trace describes a placement decision to the diagnostics handler, if any.
+func (ab *attrBuilder) trace(decision string, groups []string, attr slog.Attr, origin Origin, conflictsWith []string) {
+	if ab.diagnostics == nil {
 		return
 	}
 
-	if len(path) < len(groups) {
-		// The findAttr function resulted in a partial match. Build the remaining path.
-		remainingPath := groups[len(path):]
-		attr = buildGroupsAroundAttr(remainingPath, attr)
+	ctx := context.Background()
+	if !ab.diagnostics.Enabled(ctx, slog.LevelDebug) {
+		return
 	}
 
-	if mnt := mount.Attr; mnt.Value.Kind() == slog.KindGroup {
-		groupAttrs := append(mnt.Value.Group(), attr)
-		mnt.Value = slog.GroupValue(groupAttrs...)
-		mount.Attr = mnt
-		mount.children[attr.Key] = newAttrWithPath(&attr)
-	} else {
-		slog.Debug(logPrefix+"from (*ab).buildAttr, would mount a non-group attr onto a non-group attr",
-			slog.GroupAttrs("mount", slog.String("key", mount.Key), slog.String("val_kind", mount.Value.Kind().String())),
-			slog.GroupAttrs("attr", slog.String("key", attr.Key), slog.String("val_kind", attr.Value.Kind().String())),
-		)
+	attrs := []slog.Attr{
+		slog.String("decision", decision),
+		slog.String("path", strings.Join(appendPath(groups, attr.Key), ".")),
+		slog.String("kind", attr.Value.Kind().String()),
+		slog.String("origin", origin.String()),
+	}
+	if conflictsWith != nil {
+		attrs = append(attrs, slog.String("conflicts_with", strings.Join(conflictsWith, ".")))
 	}
+	slog.New(ab.diagnostics).LogAttrs(ctx, slog.LevelDebug, logPrefix+"placement", attrs...)
 }
 
-func buildGroupsAroundAttr(groups []string, attr slog.Attr) slog.Attr {
-	if len(groups) < 1 {
-		return attr
+func (ab *attrBuilder) reportDuplicate(groups []string, key string) {
+	if ab.duplicates == DuplicateError {
+		ab.duplicateKeys = append(ab.duplicateKeys, strings.Join(appendPath(groups, key), "."))
 	}
+}
 
-	// Start at the end of groups and build the output backwards. This results
-	// in the first group being the primary group for attr.
-	out := slog.GroupAttrs(groups[len(groups)-1], attr)
+func (ab *attrBuilder) addConflict(groups []string, node *attrNode, conflictsWith []string) {
+	ab.conflicts = append(ab.conflicts, Conflict{
+		Path:          appendPath(groups, node.attr.Key),
+		Value:         node.attr.Value,
+		Origin:        node.origin,
+		ConflictsWith: conflictsWith,
+	})
+}
 
-	for i := len(groups) - 2; i >= 0; i-- {
-		group := groups[i]
-		out = slog.GroupAttrs(group, out)
+// err describes the duplicate keys and conflicts, if they're to be reported.
+func (ab *attrBuilder) err() (err error) {
+	if len(ab.duplicateKeys) > 0 {
+		err = fmt.Errorf("%w: %s", ErrDuplicateKey, strings.Join(ab.duplicateKeys, ", "))
+	}
+	if ab.errorOnConflict {
+		err = errors.Join(err, conflictsError(ab.conflicts))
 	}
+	return
+}
+
+// appendPath makes a new path out of groups and key, without modifying the
+// memory of groups.
+func appendPath(groups []string, key string) []string {
+	return append(groups[:len(groups):len(groups)], key)
+}
+
+// lastIndex finds the last member of n with the key. The output is -1 if there
+// is none.
+func (n *attrNode) lastIndex(key string) int {
+	for i := len(n.members) - 1; i >= 0; i-- {
+		if n.members[i].attr.Key == key {
+			return i
+		}
+	}
+	return -1
+}
 
+// attrs converts the members of n to attributes. Groups without any
+// attributes are left out.
+func (n *attrNode) attrs() []slog.Attr {
+	out := make([]slog.Attr, 0, len(n.members))
+	for _, m := range n.members {
+		if !m.group {
+			out = append(out, m.attr)
+		} else if groupAttrs := m.attrs(); len(groupAttrs) > 0 {
+			out = append(out, slog.GroupAttrs(m.attr.Key, groupAttrs...))
+		}
+	}
 	return out
 }
+
+// addProvenance adds an entry to p for each non-group attribute within n.
+func (n *attrNode) addProvenance(p *Provenance, groups []string) {
+	for _, m := range n.members {
+		if m.group {
+			m.addProvenance(p, appendPath(groups, m.attr.Key))
+		} else {
+			p.add(groups, m.attr.Key, m.origin)
+		}
+	}
+}
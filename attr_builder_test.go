@@ -1,11 +1,199 @@
 package slogtesting
 
 import (
+	"errors"
 	"log/slog"
+	"slices"
+	"strings"
 	"testing"
+	"time"
 )
 
-func TestBuildGroupsAroundAttr(t *testing.T) {
+func TestBuildRecordAttrs(t *testing.T) {
+	type testCase struct {
+		name   string
+		policy DuplicateKeyPolicy
+		derive func(*slog.Logger) *slog.Logger
+		args   []any
+		exp    []slog.Attr
+		expErr string
+	}
+
+	withGroups := func(names ...string) func(*slog.Logger) *slog.Logger {
+		return func(lgr *slog.Logger) *slog.Logger {
+			for _, name := range names {
+				lgr = lgr.WithGroup(name)
+			}
+			return lgr
+		}
+	}
+
+	runTest := func(t *testing.T, test testCase) {
+		t.Helper()
+
+		lgr := slog.New(newAttrHandler(&AttrHandlerOptions{DuplicateKeys: test.policy}))
+		if test.derive != nil {
+			lgr = test.derive(lgr)
+		}
+		rec := slog.NewRecord(time.Time{}, slog.LevelInfo, "msg", 0)
+		rec.Add(test.args...)
+
+		env, err := lgr.Handler().(*attrHandler).buildRecordAttrs(rec)
+		if test.expErr == "" && err != nil {
+			t.Fatalf("unexpected error %v", err)
+		} else if test.expErr != "" {
+			if !errors.Is(err, ErrDuplicateKey) {
+				t.Fatalf("expected error (%v) to match %v", err, ErrDuplicateKey)
+			}
+			if !strings.Contains(err.Error(), test.expErr) {
+				t.Errorf("expected error message %q to contain %q", err.Error(), test.expErr)
+			}
+		}
+
+		// Leave out the builtin attributes level and msg.
+		got := GetRecordAttrs(env.Record)[2:]
+		if !slices.EqualFunc(got, test.exp, slog.Attr.Equal) {
+			t.Errorf("wrong attrs\ngot %v\nexp %v", got, test.exp)
+		}
+	}
+
+	t.Run("groups", func(t *testing.T) {
+		tests := []testCase{
+			{
+				name:   "nested WithGroup",
+				derive: withGroups("G", "H"),
+				args:   []any{"a", 1, "b", 2, "c", 3},
+				exp: []slog.Attr{
+					slog.Group("G", slog.Group("H", slog.Int("a", 1), slog.Int("b", 2), slog.Int("c", 3))),
+				},
+			},
+			{
+				name: "nested WithGroup and WithAttrs",
+				derive: func(lgr *slog.Logger) *slog.Logger {
+					return lgr.With("a", 1).WithGroup("G").With("b", 2).WithGroup("H").With("c", 3)
+				},
+				args: []any{"d", 4, slog.Group("I", "e", 5)},
+				exp: []slog.Attr{
+					slog.Int("a", 1),
+					slog.Group("G",
+						slog.Int("b", 2),
+						slog.Group("H", slog.Int("c", 3), slog.Int("d", 4), slog.Group("I", slog.Int("e", 5))),
+					),
+				},
+			},
+			{
+				name: "inline group at top level",
+				args: []any{slog.Group("", "a", 1), "b", 2},
+				exp:  []slog.Attr{slog.Int("a", 1), slog.Int("b", 2)},
+			},
+			{
+				name: "empty groups are left out",
+				derive: func(lgr *slog.Logger) *slog.Logger {
+					return lgr.WithGroup("G").WithGroup("H")
+				},
+				args: []any{slog.Group("I")},
+				exp:  []slog.Attr{},
+			},
+		}
+
+		for _, test := range tests {
+			t.Run(test.name, func(t *testing.T) { runTest(t, test) })
+		}
+	})
+
+	t.Run("duplicate keys", func(t *testing.T) {
+		tests := []testCase{
+			{
+				name:   "merge groups, scalars",
+				policy: DuplicateMergeGroups,
+				args:   []any{"a", 1, "a", 2},
+				exp:    []slog.Attr{slog.Int("a", 1), slog.Int("a", 2)},
+			},
+			{
+				name:   "merge groups, groups",
+				policy: DuplicateMergeGroups,
+				derive: func(lgr *slog.Logger) *slog.Logger { return lgr.With(slog.Group("G", "a", 1)) },
+				args:   []any{slog.Group("G", "a", 2, "b", 3)},
+				exp:    []slog.Attr{slog.Group("G", slog.Int("a", 1), slog.Int("a", 2), slog.Int("b", 3))},
+			},
+			{
+				name:   "merge groups, group onto scalar",
+				policy: DuplicateMergeGroups,
+				derive: func(lgr *slog.Logger) *slog.Logger { return lgr.With("G", 1).WithGroup("G") },
+				args:   []any{"a", 2},
+				exp:    []slog.Attr{slog.Int("G", 1)},
+			},
+			{
+				name:   "merge groups, scalar after group",
+				policy: DuplicateMergeGroups,
+				args:   []any{slog.Group("G", "a", 1), "G", 2},
+				exp:    []slog.Attr{slog.Group("G", slog.Int("a", 1)), slog.Int("G", 2)},
+			},
+			{
+				name:   "keep all, groups",
+				policy: DuplicateKeepAll,
+				derive: func(lgr *slog.Logger) *slog.Logger { return lgr.With(slog.Group("G", "a", 1)).WithGroup("G") },
+				args:   []any{"a", 2, slog.Group("H", "b", 3), slog.Group("H", "b", 4)},
+				exp: []slog.Attr{
+					slog.Group("G", slog.Int("a", 1)),
+					slog.Group("G", slog.Int("a", 2), slog.Group("H", slog.Int("b", 3)), slog.Group("H", slog.Int("b", 4))),
+				},
+			},
+			{
+				name:   "keep all, group and scalar",
+				policy: DuplicateKeepAll,
+				derive: func(lgr *slog.Logger) *slog.Logger { return lgr.With("G", 1).WithGroup("G") },
+				args:   []any{"a", 2},
+				exp:    []slog.Attr{slog.Int("G", 1), slog.Group("G", slog.Int("a", 2))},
+			},
+			{
+				name:   "first wins",
+				policy: DuplicateFirstWins,
+				args:   []any{"a", 1, slog.Group("G", "b", 2), "a", 3, slog.Group("G", "b", 4, "c", 5), "G", 6},
+				exp:    []slog.Attr{slog.Int("a", 1), slog.Group("G", slog.Int("b", 2), slog.Int("c", 5))},
+			},
+			{
+				name:   "first wins, group onto scalar",
+				policy: DuplicateFirstWins,
+				derive: func(lgr *slog.Logger) *slog.Logger { return lgr.With("G", 1).WithGroup("G") },
+				args:   []any{"a", 2},
+				exp:    []slog.Attr{slog.Int("G", 1)},
+			},
+			{
+				name:   "last wins",
+				policy: DuplicateLastWins,
+				args:   []any{"a", 1, slog.Group("G", "b", 2), "a", 3, slog.Group("G", "b", 4, "c", 5)},
+				exp:    []slog.Attr{slog.Int("a", 3), slog.Group("G", slog.Int("b", 4), slog.Int("c", 5))},
+			},
+			{
+				name:   "last wins, group onto scalar",
+				policy: DuplicateLastWins,
+				derive: func(lgr *slog.Logger) *slog.Logger { return lgr.With("G", 1, "z", 0).WithGroup("G") },
+				args:   []any{"a", 2},
+				exp:    []slog.Attr{slog.Group("G", slog.Int("a", 2)), slog.Int("z", 0)},
+			},
+			{
+				name:   "error",
+				policy: DuplicateError,
+				derive: withGroups("G"),
+				args:   []any{"a", 1, "a", 2, slog.Group("H", "b", 3), slog.Group("H", "c", 4)},
+				exp: []slog.Attr{
+					slog.Group("G", slog.Int("a", 1), slog.Int("a", 2), slog.Group("H", slog.Int("b", 3), slog.Int("c", 4))),
+				},
+				expErr: "G.a, G.H",
+			},
+		}
+
+		for _, test := range tests {
+			t.Run(test.name, func(t *testing.T) { runTest(t, test) })
+		}
+	})
+}
+
+// TestBuildAttrGroupsAroundAttr has the expectations of the tests for
+// buildGroupsAroundAttr, which the attribute tree replaced. An attribute placed
+// within groups which do not exist yet is wrapped in those groups.
+func TestBuildAttrGroupsAroundAttr(t *testing.T) {
 	tests := []struct {
 		name   string
 		groups []string
@@ -60,12 +248,86 @@ func TestBuildGroupsAroundAttr(t *testing.T) {
 
 	for _, test := range tests {
 		t.Run(test.name, func(t *testing.T) {
-			got := buildGroupsAroundAttr(test.groups, test.attr)
-			if !got.Equal(test.exp) {
-				t.Errorf(
-					"wrong Attr\ngot_key %q, exp_key %q\ngot_val_kind %q, exp_val_kind %q\ngot_val %v exp_val %v",
-					got.Key, test.exp.Key, got.Value.Kind().String(), test.exp.Value.Kind().String(), got.Value, test.exp.Value,
-				)
+			var ab attrBuilder
+			ab.buildAttr(test.groups, test.attr)
+
+			got := ab.root.attrs()
+			if len(got) != 1 || !got[0].Equal(test.exp) {
+				t.Errorf("wrong attrs\ngot %v\nexp %v", got, test.exp)
+			}
+		})
+	}
+}
+
+// TestBuildAttrIntoExistingGroups has the expectations of the tests for
+// findAttr, which the attribute tree replaced. An attribute placed within
+// groups is mounted onto the deepest existing group along the path, and the
+// rest of the path is created. A path through a non-group attribute cannot be
+// mounted, so the attribute is dropped.
+func TestBuildAttrIntoExistingGroups(t *testing.T) {
+	msg := slog.String(slog.MessageKey, "message")
+	c := slog.String("c", "charlie")
+	h := slog.String("H", "Hotel")
+	x := slog.String("x", "xray")
+
+	tests := []struct {
+		name         string
+		groups       []string
+		exp          []slog.Attr
+		expConflicts int
+	}{
+		{
+			name:   "empty",
+			groups: []string{},
+			exp:    []slog.Attr{msg, slog.Group("G", c, h), x},
+		},
+		{
+			name:   "top-level not found",
+			groups: []string{"foo"},
+			exp:    []slog.Attr{msg, slog.Group("G", c, h), slog.Group("foo", x)},
+		},
+		{
+			name:   "sub-level full match",
+			groups: []string{"G"},
+			exp:    []slog.Attr{msg, slog.Group("G", c, h, x)},
+		},
+		{
+			name:   "sub-level partial match",
+			groups: []string{"G", "sibling"},
+			exp:    []slog.Attr{msg, slog.Group("G", c, h, slog.Group("sibling", x))},
+		},
+		{
+			name:         "sub-level match on non-group",
+			groups:       []string{"G", "H"},
+			exp:          []slog.Attr{msg, slog.Group("G", c, h)},
+			expConflicts: 1,
+		},
+		{
+			name:         "sub-level path too long",
+			groups:       []string{"G", "H", "I"},
+			exp:          []slog.Attr{msg, slog.Group("G", c, h)},
+			expConflicts: 1,
+		},
+		{
+			name:         "reaches too deep from top level",
+			groups:       []string{slog.MessageKey, "x"},
+			exp:          []slog.Attr{msg, slog.Group("G", c, h)},
+			expConflicts: 1,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			var ab attrBuilder
+			ab.buildAttr(nil, msg)
+			ab.buildAttr(nil, slog.Group("G", c, h))
+			ab.buildAttr(test.groups, x)
+
+			if got := ab.root.attrs(); !slices.EqualFunc(got, test.exp, slog.Attr.Equal) {
+				t.Errorf("wrong attrs\ngot %v\nexp %v", got, test.exp)
+			}
+			if len(ab.conflicts) != test.expConflicts {
+				t.Errorf("wrong number of conflicts; got %d, expected %d", len(ab.conflicts), test.expConflicts)
 			}
 		})
 	}
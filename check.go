@@ -71,6 +71,50 @@ func HasMatch(m func(slog.Attr) bool) Check {
 	}
 }
 
+// HasSource makes a Check for the presence of an attribute with the key
+// [slog.SourceKey], whose value is a *[slog.Source] or a [slog.Source]. The
+// source file must end with fileSuffix. The source function must either equal
+// funcName, or end with funcName after a "." or "/". This allows for short
+// function names, such as "TestFoo" or "pkg.TestFoo". An empty fileSuffix or
+// funcName is not checked. See the AddSource field of [slog.HandlerOptions].
+// The Check will return an error unless a matching attribute is found in attrs.
+func HasSource(fileSuffix, funcName string) Check {
+	return func(attrs []slog.Attr) (err error) {
+		matchKey := makeKeyMatcher(slog.SourceKey)
+		gotMatches, err := collectNMatchingAttrs(attrs, 1, matchKey)
+		if err != nil {
+			err = fmt.Errorf("looking for attr with key %s: %v", slog.SourceKey, err)
+			return
+		}
+
+		var src *slog.Source
+		switch val := gotMatches[0].Value.Any().(type) {
+		case *slog.Source:
+			src = val
+		case slog.Source:
+			src = &val
+		}
+		if src == nil {
+			err = fmt.Errorf("wrong value type (%T) for attr with key %s", gotMatches[0].Value.Any(), slog.SourceKey)
+			return
+		}
+
+		var errs []error
+		if fileSuffix != "" && !strings.HasSuffix(src.File, fileSuffix) {
+			errs = append(errs, fmt.Errorf("source file %q does not end with %q", src.File, fileSuffix))
+		}
+		if funcName != "" && !matchFuncName(src.Function, funcName) {
+			errs = append(errs, fmt.Errorf("source function %q does not match %q", src.Function, funcName))
+		}
+		err = errors.Join(errs...)
+		return
+	}
+}
+
+func matchFuncName(got, want string) bool {
+	return got == want || strings.HasSuffix(got, "."+want) || strings.HasSuffix(got, "/"+want)
+}
+
 // InGroup makes a Check for a Check in a group with a matching name. The output
 // Check will first look for group with a given name, then run all of the input
 // Checks upon the attributes in the group, and then combine non-nil errors into
@@ -114,7 +158,51 @@ func InGroup(name string, c Check, moreChecks ...Check) Check {
 	}
 }
 
+// walkAttrs calls visit for each attribute in attrs and, recursively, in the
+// groups within them, in the order they appear. The groups are the keys of the
+// groups around the attribute, outermost first. Values are resolved first.
+// Where InGroup steps into 1 named group at a time, this finds attributes at
+// any depth, as for path expressions and queries.
+func walkAttrs(attrs []slog.Attr, visit func(groups []string, a slog.Attr)) {
+	var walk func(groups []string, attrs []slog.Attr)
+	walk = func(groups []string, attrs []slog.Attr) {
+		for _, a := range attrs {
+			a.Value = a.Value.Resolve()
+			visit(groups, a)
+			if a.Value.Kind() == slog.KindGroup {
+				walk(appendPath(groups, a.Key), a.Value.Group())
+			}
+		}
+	}
+	walk(nil, attrs)
+}
+
 func makeErrorWithGroupPath(err error, groupName string) error {
+	// An error may be a tree of errors, as from a combinator or errors.Join.
+	// Add the group to each branch, rather than only to the first one found.
+	switch e := err.(type) {
+	case *checkError:
+		out := *e
+		out.errs = make([]error, len(e.errs))
+		for i, child := range e.errs {
+			out.errs[i] = makeErrorWithGroupPath(child, groupName)
+		}
+		if len(out.errs) > 0 {
+			return &out
+		}
+	case interface{ Unwrap() []error }:
+		children := e.Unwrap()
+		if len(children) == 1 {
+			return makeErrorWithGroupPath(children[0], groupName)
+		} else if len(children) > 1 {
+			errs := make([]error, len(children))
+			for i, child := range children {
+				errs[i] = makeErrorWithGroupPath(child, groupName)
+			}
+			return errors.Join(errs...)
+		}
+	}
+
 	var errWithPath *errorWithGroupPath
 	if !errors.As(err, &errWithPath) {
 		err = &errorWithGroupPath{
@@ -24,6 +24,40 @@ func TestCheck(t *testing.T) {
 	}
 }
 
+func TestHasSource(t *testing.T) {
+	src := slog.Source{Function: "example.com/pkg.(*T).Method", File: "/src/pkg/file.go", Line: 10}
+
+	tests := []struct {
+		name       string
+		attrs      []slog.Attr
+		fileSuffix string
+		funcName   string
+		expErr     bool
+	}{
+		{name: "pointer value", attrs: []slog.Attr{slog.Any(slog.SourceKey, &src)}, fileSuffix: "file.go", funcName: "(*T).Method"},
+		{name: "non-pointer value", attrs: []slog.Attr{slog.Any(slog.SourceKey, src)}, fileSuffix: "pkg/file.go", funcName: "pkg.(*T).Method"},
+		{name: "full func name", attrs: []slog.Attr{slog.Any(slog.SourceKey, &src)}, funcName: src.Function},
+		{name: "empty inputs", attrs: []slog.Attr{slog.Any(slog.SourceKey, &src)}},
+		{name: "missing", attrs: []slog.Attr{slog.String("foo", "bar")}, expErr: true},
+		{name: "wrong type", attrs: []slog.Attr{slog.String(slog.SourceKey, "file.go:10")}, expErr: true},
+		{name: "wrong file", attrs: []slog.Attr{slog.Any(slog.SourceKey, &src)}, fileSuffix: "other.go", expErr: true},
+		{name: "method name", attrs: []slog.Attr{slog.Any(slog.SourceKey, &src)}, funcName: "Method"},
+		{name: "partial func name", attrs: []slog.Attr{slog.Any(slog.SourceKey, &src)}, funcName: "thod", expErr: true},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			err := st.HasSource(test.fileSuffix, test.funcName)(test.attrs)
+			if test.expErr && err == nil {
+				t.Fatal("expected an error but got nil")
+			} else if !test.expErr && err != nil {
+				t.Fatalf("unexpected error %v", err)
+			}
+			t.Log(err)
+		})
+	}
+}
+
 func TestCheckGroups(t *testing.T) {
 	attrs := []slog.Attr{
 		slog.Time(slog.TimeKey, time.Now()),
@@ -0,0 +1,52 @@
+package slogtesting
+
+import (
+	"sync/atomic"
+	"time"
+)
+
+// A Clock provides the times of captured records, in place of the times set by
+// the logger. See the Clock field of [AttrHandlerOptions].
+type Clock func() time.Time
+
+// FixedClock always outputs t.
+func FixedClock(t time.Time) Clock {
+	return func() time.Time { return t }
+}
+
+// StepClock outputs start, then each following call outputs a time that is
+// step later than the previous one. It's safe for concurrent use, though the
+// order of the times then depends upon the order of the calls.
+func StepClock(start time.Time, step time.Duration) Clock {
+	var numCalls atomic.Int64
+	return func() time.Time {
+		n := numCalls.Add(1) - 1
+		return start.Add(time.Duration(n) * step)
+	}
+}
+
+// recordTime normalizes the time of a record according to the handler
+// options. A zero time stays zero, because a handler ignores it.
+func (h *attrHandler) recordTime(t time.Time) time.Time {
+	if t.IsZero() {
+		return t
+	}
+	if h.opts.Clock != nil {
+		t = h.opts.Clock()
+	}
+	return h.normalizeTime(t)
+}
+
+// normalizeTime is like recordTime, but it does not call the Clock.
+func (h *attrHandler) normalizeTime(t time.Time) time.Time {
+	if t.IsZero() {
+		return t
+	}
+	if h.opts.UTC {
+		t = t.UTC()
+	}
+	if h.opts.TruncateTime > 0 {
+		t = t.Truncate(h.opts.TruncateTime)
+	}
+	return t
+}
@@ -0,0 +1,89 @@
+package slogtesting_test
+
+import (
+	"context"
+	"log/slog"
+	"testing"
+	"time"
+
+	st "github.com/rafaelespinoza/slogtesting"
+)
+
+func TestClock(t *testing.T) {
+	start := time.Date(2025, 11, 1, 12, 30, 45, 123456789, time.FixedZone("X", -7*60*60))
+
+	tests := []struct {
+		name string
+		opts st.AttrHandlerOptions
+		exp  []time.Time
+	}{
+		{
+			name: "fixed",
+			opts: st.AttrHandlerOptions{Clock: st.FixedClock(start)},
+			exp:  []time.Time{start, start, start},
+		},
+		{
+			name: "step",
+			opts: st.AttrHandlerOptions{Clock: st.StepClock(start, time.Second)},
+			exp:  []time.Time{start, start.Add(time.Second), start.Add(2 * time.Second)},
+		},
+		{
+			name: "func",
+			opts: st.AttrHandlerOptions{Clock: func() time.Time { return start.AddDate(1, 0, 0) }},
+			exp:  []time.Time{start.AddDate(1, 0, 0), start.AddDate(1, 0, 0), start.AddDate(1, 0, 0)},
+		},
+		{
+			name: "UTC and truncate",
+			opts: st.AttrHandlerOptions{Clock: st.FixedClock(start), UTC: true, TruncateTime: time.Second},
+			exp: []time.Time{
+				time.Date(2025, 11, 1, 19, 30, 45, 0, time.UTC),
+				time.Date(2025, 11, 1, 19, 30, 45, 0, time.UTC),
+				time.Date(2025, 11, 1, 19, 30, 45, 0, time.UTC),
+			},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			var seen []time.Time
+			test.opts.ReplaceAttr = func(groups []string, a slog.Attr) slog.Attr {
+				if len(groups) == 0 && a.Key == slog.TimeKey {
+					seen = append(seen, a.Value.Time())
+				}
+				return a
+			}
+			rec := st.NewRecorder(&test.opts)
+			logger := slog.New(rec.Handler())
+			for range len(test.exp) {
+				logger.Info("msg")
+			}
+
+			records := rec.Records()
+			requireResultLen(t, records, len(test.exp))
+			for i, r := range records {
+				if !r.Time.Equal(test.exp[i]) || r.Time.Location() != test.exp[i].Location() {
+					t.Errorf("record[%d] wrong Time; got %v, expected %v", i, r.Time, test.exp[i])
+				}
+				if !seen[i].Equal(test.exp[i]) {
+					t.Errorf("record[%d] ReplaceAttr got %v, expected %v", i, seen[i], test.exp[i])
+				}
+			}
+		})
+	}
+
+	t.Run("zero time", func(t *testing.T) {
+		rec := st.NewRecorder(&st.AttrHandlerOptions{Clock: st.FixedClock(start)})
+		err := rec.Handler().Handle(context.Background(), slog.NewRecord(time.Time{}, slog.LevelInfo, "msg", 0))
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		got, _ := rec.Last()
+		if !got.Time.IsZero() {
+			t.Errorf("expected zero time; got %v", got.Time)
+		}
+		if err := st.MissingKey(slog.TimeKey)(st.GetRecordAttrs(got)); err != nil {
+			t.Error(err)
+		}
+	})
+}
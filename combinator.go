@@ -0,0 +1,127 @@
+package slogtesting
+
+import (
+	"fmt"
+	"log/slog"
+	"slices"
+	"strings"
+)
+
+// All makes a Check which passes when each of the checks passes.
+func All(checks ...Check) Check {
+	return func(attrs []slog.Attr) error {
+		errs := runChecks(attrs, checks)
+		if len(errs) < 1 {
+			return nil
+		}
+		return &checkError{
+			op:   "All",
+			msg:  fmt.Sprintf("%d of %d checks failed", len(errs), len(checks)),
+			errs: errs,
+		}
+	}
+}
+
+// Any makes a Check which passes when at least 1 of the checks passes.
+func Any(checks ...Check) Check {
+	return func(attrs []slog.Attr) error {
+		errs := runChecks(attrs, checks)
+		if len(errs) < len(checks) {
+			return nil
+		}
+		return &checkError{
+			op:   "Any",
+			msg:  fmt.Sprintf("none of %d checks passed", len(checks)),
+			errs: errs,
+		}
+	}
+}
+
+// Not makes a Check which passes when c fails.
+func Not(c Check) Check {
+	return func(attrs []slog.Attr) error {
+		if err := c(attrs); err != nil {
+			return nil
+		}
+		return &checkError{op: "Not", msg: "check passed"}
+	}
+}
+
+// AtLeast makes a Check which passes when at least n of the checks pass.
+func AtLeast(n int, checks ...Check) Check {
+	return func(attrs []slog.Attr) error {
+		errs := runChecks(attrs, checks)
+		if numPassed := len(checks) - len(errs); numPassed < n {
+			return &checkError{
+				op:   fmt.Sprintf("AtLeast(%d)", n),
+				msg:  fmt.Sprintf("%d of %d checks passed", numPassed, len(checks)),
+				errs: errs,
+			}
+		}
+		return nil
+	}
+}
+
+// Exactly makes a Check which passes when exactly n of the checks pass. When
+// too many checks pass, the error lists the ones which failed, if any.
+func Exactly(n int, checks ...Check) Check {
+	return func(attrs []slog.Attr) error {
+		errs := runChecks(attrs, checks)
+		if numPassed := len(checks) - len(errs); numPassed != n {
+			return &checkError{
+				op:   fmt.Sprintf("Exactly(%d)", n),
+				msg:  fmt.Sprintf("%d of %d checks passed", numPassed, len(checks)),
+				errs: errs,
+			}
+		}
+		return nil
+	}
+}
+
+// Optional makes a Check which runs c only when there's an attribute with the
+// key. Without one, it passes.
+func Optional(key string, c Check) Check {
+	return func(attrs []slog.Attr) error {
+		matchKey := makeKeyMatcher(key)
+		if got := collectMatchingAttrs(attrs, matchKey); len(got) < 1 {
+			return nil
+		}
+		return c(attrs)
+	}
+}
+
+func runChecks(attrs []slog.Attr, checks []Check) (errs []error) {
+	for _, check := range checks {
+		if err := check(attrs); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return
+}
+
+// checkError is the error of a combinator. It has the errors of the checks
+// which failed, which may themselves be from combinators, so that the output
+// of Error is a tree of nested failures. Use errors.As or errors.Is to look
+// within it, as with an error from [errors.Join].
+type checkError struct {
+	op   string
+	msg  string
+	errs []error
+}
+
+func (e *checkError) Error() string {
+	var sb strings.Builder
+	sb.WriteString(e.op + ": " + e.msg)
+	for _, err := range e.errs {
+		for i, line := range strings.Split(err.Error(), "\n") {
+			if i == 0 {
+				sb.WriteString("\n  - " + line)
+			} else {
+				sb.WriteString("\n    " + line)
+			}
+		}
+	}
+	return sb.String()
+}
+
+func (e *checkError) Unwrap() []error { return slices.Clone(e.errs) }
@@ -0,0 +1,96 @@
+package slogtesting_test
+
+import (
+	"errors"
+	"log/slog"
+	"slices"
+	"strings"
+	"testing"
+
+	st "github.com/rafaelespinoza/slogtesting"
+)
+
+func TestCombinators(t *testing.T) {
+	attrs := []slog.Attr{
+		slog.String("a", "b"),
+		slog.Group("G", slog.String("c", "d"), slog.Group("H", slog.String("e", "f"))),
+	}
+	pass, fail := st.HasKey("a"), st.HasKey("z")
+
+	tests := []struct {
+		name   string
+		check  st.Check
+		expErr bool
+	}{
+		{name: "All", check: st.All(pass, st.HasAttr(slog.String("a", "b")))},
+		{name: "All fail", check: st.All(pass, fail), expErr: true},
+		{name: "All empty", check: st.All()},
+		{name: "Any", check: st.Any(fail, pass)},
+		{name: "Any fail", check: st.Any(fail, fail), expErr: true},
+		{name: "Not", check: st.Not(fail)},
+		{name: "Not fail", check: st.Not(pass), expErr: true},
+		{name: "AtLeast", check: st.AtLeast(2, pass, fail, pass)},
+		{name: "AtLeast fail", check: st.AtLeast(2, pass, fail, fail), expErr: true},
+		{name: "Exactly", check: st.Exactly(1, pass, fail)},
+		{name: "Exactly too few", check: st.Exactly(2, pass, fail), expErr: true},
+		{name: "Exactly too many", check: st.Exactly(1, pass, pass), expErr: true},
+		{name: "Optional missing", check: st.Optional("z", fail)},
+		{name: "Optional present", check: st.Optional("a", st.HasAttr(slog.String("a", "b")))},
+		{name: "Optional present fail", check: st.Optional("a", st.HasAttr(slog.String("a", "x"))), expErr: true},
+		{name: "nested", check: st.Any(st.All(pass, fail), st.InGroup("G", st.Not(st.HasKey("z"))))},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			err := test.check(attrs)
+			if test.expErr && err == nil {
+				t.Fatal("expected an error but got nil")
+			} else if !test.expErr && err != nil {
+				t.Fatalf("unexpected error %v", err)
+			}
+			t.Log(err)
+		})
+	}
+
+	t.Run("error tree", func(t *testing.T) {
+		check := st.InGroup("G", st.Any(
+			st.HasKey("x"),
+			st.InGroup("H", st.All(st.HasKey("y"), st.Not(st.HasKey("e")))),
+		))
+
+		err := check(attrs)
+		if err == nil {
+			t.Fatal("expected an error but got nil")
+		}
+		t.Log(err)
+
+		msg := err.Error()
+		for _, want := range []string{"Any: none of 2 checks passed", "  - All: 2 of 2 checks failed", "    - Not: check passed"} {
+			if !strings.Contains(msg, want) {
+				t.Errorf("expected error message to contain %q", want)
+			}
+		}
+
+		// Each leaf of the tree has its own group path.
+		var gotPaths [][]string
+		var walk func(error)
+		walk = func(err error) {
+			if e, ok := err.(interface{ GroupPath() []string }); ok {
+				gotPaths = append(gotPaths, e.GroupPath())
+			}
+			if e, ok := err.(interface{ Unwrap() []error }); ok {
+				for _, child := range e.Unwrap() {
+					walk(child)
+				}
+			} else if child := errors.Unwrap(err); child != nil {
+				walk(child)
+			}
+		}
+		walk(err)
+
+		expPaths := [][]string{{"G"}, {"G", "H"}, {"G", "H"}}
+		if !slices.EqualFunc(gotPaths, expPaths, slices.Equal) {
+			t.Errorf("wrong group paths; got %q, expected %q", gotPaths, expPaths)
+		}
+	})
+}
@@ -0,0 +1,56 @@
+package slogtesting
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+	"strings"
+)
+
+// A Conflict describes an attribute which was dropped from a captured record
+// because it's a group whose key was already used at the same level by a
+// non-group attribute. Everything within a dropped group is reported, 1
+// Conflict per attribute.
+// With the [DuplicateMergeGroups] and [DuplicateError] policies, these are
+// the only attributes the handler drops. See the Conflicts field of
+// [Envelope].
+type Conflict struct {
+	// Path is the keys of the groups of the dropped attribute, followed by
+	// its own key.
+	Path []string
+	// Value is the value of the dropped attribute.
+	Value slog.Value
+	// Origin is where the dropped attribute came from.
+	Origin Origin
+	// ConflictsWith is the path to the attribute that was already placed.
+	ConflictsWith []string
+}
+
+func (c Conflict) String() string {
+	return fmt.Sprintf("%s (%s) was dropped because of %s",
+		strings.Join(c.Path, "."), c.Origin, strings.Join(c.ConflictsWith, "."),
+	)
+}
+
+// ErrConflict is reported for records with at least 1 [Conflict] when the
+// ErrorOnConflict field of [AttrHandlerOptions] is true, and by
+// [NoDroppedAttrs].
+var ErrConflict = errors.New(logPrefix + "attribute conflict")
+
+// NoDroppedAttrs fails if any attribute was dropped from the record because of
+// a [Conflict].
+func NoDroppedAttrs() EnvelopeCheck {
+	return func(e Envelope) error { return conflictsError(e.Conflicts) }
+}
+
+func conflictsError(conflicts []Conflict) error {
+	if len(conflicts) < 1 {
+		return nil
+	}
+
+	msgs := make([]string, len(conflicts))
+	for i, c := range conflicts {
+		msgs[i] = c.String()
+	}
+	return fmt.Errorf("%w: %s", ErrConflict, strings.Join(msgs, "; "))
+}
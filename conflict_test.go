@@ -0,0 +1,84 @@
+package slogtesting_test
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"slices"
+	"testing"
+	"time"
+
+	st "github.com/rafaelespinoza/slogtesting"
+)
+
+func TestConflicts(t *testing.T) {
+	t.Run("reported", func(t *testing.T) {
+		rec := st.NewRecorder(nil)
+		logger := slog.New(rec.Handler())
+
+		logger.Info("ok", "G", 1, "a", 2)
+		logger.With("G", 1).WithGroup("G").Info("group onto non-group", "a", 2, slog.Group("H", "b", 3))
+		logger.Info("group onto non-group", "G", 2, slog.Group("G", "a", 1))
+		logger.Info("non-group onto group", slog.Group("G", "a", 1), "G", 2)
+
+		envelopes := rec.Envelopes()
+		requireResultLen(t, envelopes, 4)
+
+		if err := st.NoDroppedAttrs()(envelopes[0]); err != nil {
+			t.Errorf("unexpected error %v", err)
+		}
+
+		conflicts := envelopes[1].Conflicts
+		requireResultLen(t, conflicts, 2)
+		if got := conflicts[0]; !slices.Equal(got.Path, []string{"G", "a"}) || !slices.Equal(got.ConflictsWith, []string{"G"}) {
+			t.Errorf("wrong conflict %s", got)
+		}
+		if got := conflicts[1]; !slices.Equal(got.Path, []string{"G", "H", "b"}) || got.Origin != st.OriginCallSite {
+			t.Errorf("wrong conflict %s", got)
+		}
+		err := st.NoDroppedAttrs()(envelopes[1])
+		if !errors.Is(err, st.ErrConflict) {
+			t.Errorf("expected error (%v) to match %v", err, st.ErrConflict)
+		}
+		t.Log(err)
+
+		conflicts = envelopes[2].Conflicts
+		requireResultLen(t, conflicts, 1)
+		if got := conflicts[0]; !slices.Equal(got.Path, []string{"G", "a"}) || got.Value.Int64() != 1 {
+			t.Errorf("wrong conflict %s", got)
+		}
+
+		// A non-group attribute is kept, like in the output of a
+		// slog.JSONHandler.
+		if err := st.NoDroppedAttrs()(envelopes[3]); err != nil {
+			t.Errorf("unexpected error %v", err)
+		}
+	})
+
+	t.Run("not for duplicate key policies which drop attributes", func(t *testing.T) {
+		for _, policy := range []st.DuplicateKeyPolicy{st.DuplicateKeepAll, st.DuplicateFirstWins, st.DuplicateLastWins} {
+			rec := st.NewRecorder(&st.AttrHandlerOptions{DuplicateKeys: policy})
+			slog.New(rec.Handler()).With("G", 1).WithGroup("G").Info("msg", "a", 2)
+
+			if err := st.NoDroppedAttrs()(rec.Envelopes()[0]); err != nil {
+				t.Errorf("policy %d, unexpected error %v", policy, err)
+			}
+		}
+	})
+
+	t.Run("error on conflict", func(t *testing.T) {
+		var captured []slog.Record
+		h := st.NewAttrHandler(&st.AttrHandlerOptions{
+			CaptureRecord:   func(r slog.Record) error { captured = append(captured, r); return nil },
+			ErrorOnConflict: true,
+		})
+
+		r := slog.NewRecord(time.Now(), slog.LevelInfo, "msg", 0)
+		r.Add("a", 2)
+		err := h.WithAttrs([]slog.Attr{slog.Int("G", 1)}).WithGroup("G").Handle(context.Background(), r)
+		if !errors.Is(err, st.ErrConflict) {
+			t.Errorf("expected error (%v) to match %v", err, st.ErrConflict)
+		}
+		requireResultLen(t, captured, 1)
+	})
+}
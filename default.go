@@ -0,0 +1,58 @@
+package slogtesting
+
+import (
+	"log"
+	"log/slog"
+	"sync/atomic"
+)
+
+// capturingDefault guards against concurrent use of CaptureDefault.
+var capturingDefault atomic.Bool
+
+// CaptureDefault installs the handler of a new [Recorder] as the handler of
+// the default logger, via [slog.SetDefault], for the rest of the test. This is
+// for observing code which logs with the top-level functions, such as
+// [slog.Info], instead of a logger that could be injected. Because of how
+// slog.SetDefault works, the output of the standard [log] package is also
+// written to the Recorder, at the level set by [slog.SetLogLoggerLevel].
+//
+// The previous default logger, and the output and flags of the log package,
+// are restored in a function registered with tb's Cleanup method. If the
+// default logger was replaced by something else in the meantime, then the
+// test is marked as failed.
+//
+// The default logger is global state, so this function is not safe to use
+// from parallel tests. As a guard, calling it while another CaptureDefault is
+// active fails the test with Fatalf, and the output is nil. For parallel tests,
+// see [Router].
+func CaptureDefault(tb TB, opts *AttrHandlerOptions) *Recorder {
+	tb.Helper()
+
+	if !capturingDefault.CompareAndSwap(false, true) {
+		tb.Fatalf(logPrefix + "CaptureDefault is already active; it cannot be used from concurrent or parallel tests")
+		return nil
+	}
+
+	prevLogger := slog.Default()
+	prevWriter, prevFlags := log.Writer(), log.Flags()
+
+	out := NewRecorder(opts)
+	slog.SetDefault(slog.New(out.Handler()))
+
+	tb.Cleanup(func() {
+		defer capturingDefault.Store(false)
+
+		if slog.Default().Handler() != out.Handler() {
+			tb.Errorf(logPrefix + "default logger was replaced while CaptureDefault was active")
+		}
+
+		slog.SetDefault(prevLogger)
+		// Restoring the previous slog default does not always restore the log
+		// package. In particular, if the previous default logger was the
+		// original one, then slog.SetDefault leaves the log package alone.
+		log.SetOutput(prevWriter)
+		log.SetFlags(prevFlags)
+	})
+
+	return out
+}
@@ -0,0 +1,68 @@
+package slogtesting_test
+
+import (
+	"log"
+	"log/slog"
+	"testing"
+
+	st "github.com/rafaelespinoza/slogtesting"
+)
+
+func TestCaptureDefault(t *testing.T) {
+	prevLogger := slog.Default()
+	prevWriter, prevFlags := log.Writer(), log.Flags()
+
+	t.Run("capture", func(t *testing.T) {
+		rec := st.CaptureDefault(t, nil)
+
+		slog.Info("from slog", "a", "b")
+		slog.Debug("not captured")
+		log.Print("from log")
+
+		records := rec.Records()
+		requireResultLen(t, records, 2)
+
+		check := st.HasAttr(slog.String("a", "b"))
+		if err := check(st.GetRecordAttrs(records[0])); err != nil {
+			t.Error(err)
+		}
+		if records[1].Message != "from log" {
+			t.Errorf("wrong Message; got %q, expected %q", records[1].Message, "from log")
+		}
+	})
+
+	if slog.Default() != prevLogger {
+		t.Error("expected default logger to be restored")
+	}
+	if log.Writer() != prevWriter || log.Flags() != prevFlags {
+		t.Error("expected log package output and flags to be restored")
+	}
+
+	t.Run("guard", func(t *testing.T) {
+		_ = st.CaptureDefault(t, nil)
+
+		var fake st.FakeTB
+		if got := st.CaptureDefault(&fake, nil); got != nil {
+			t.Error("expected nil Recorder")
+		}
+		if fatals := fake.Fatals(); len(fatals) != 1 {
+			t.Errorf("wrong number of calls to Fatalf; got %d, expected %d", len(fatals), 1)
+		}
+		t.Log(fake.Fatals())
+	})
+
+	t.Run("replaced while active", func(t *testing.T) {
+		var fake st.FakeTB
+		fake.Run(func(tb st.TB) {
+			_ = st.CaptureDefault(tb, nil)
+			slog.SetDefault(slog.New(st.NewAttrHandler(nil)))
+		})
+
+		if errs := fake.Errors(); len(errs) != 1 {
+			t.Errorf("wrong number of calls to Errorf; got %d, expected %d", len(errs), 1)
+		}
+		if slog.Default() != prevLogger {
+			t.Error("expected default logger to be restored")
+		}
+	})
+}
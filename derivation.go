@@ -0,0 +1,116 @@
+package slogtesting
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+	"slices"
+)
+
+// A DerivationStep is 1 call to the WithGroup or WithAttrs method of a handler.
+// Exactly 1 of its fields is non-empty.
+type DerivationStep struct {
+	Group string
+	Attrs []slog.Attr
+}
+
+// deriver is implemented by handlers in this package which accumulate data
+// from WithGroup and WithAttrs.
+type deriver interface {
+	groupsOrAttrs() *groupsOrAttrs
+}
+
+func (h *attrHandler) groupsOrAttrs() *groupsOrAttrs   { return h.goas }
+func (h *routerHandler) groupsOrAttrs() *groupsOrAttrs { return h.goas }
+
+// Derivation lists the steps taken to derive h, oldest first. This allows for
+// testing how a logger is constructed, such as checking that a per-request
+// logger carries a request ID, before it outputs any record. Pass the output
+// to a [DerivationCheck].
+//
+// It supports the handlers made by this package, such as the ones from
+// [NewAttrHandler], [Recorder.Handler] and [Router.Handler]. For any other
+// handler, it returns an error. Use [slog.Logger.Handler] to get the handler of
+// a logger.
+func Derivation(h slog.Handler) ([]DerivationStep, error) {
+	d, ok := h.(deriver)
+	if !ok {
+		return nil, fmt.Errorf("%sunsupported handler type %T", logPrefix, h)
+	}
+
+	var out []DerivationStep
+	for g := d.groupsOrAttrs(); g != nil; g = g.Next {
+		out = append(out, DerivationStep{Group: g.Group, Attrs: slices.Clone(g.Attrs)})
+	}
+	slices.Reverse(out)
+	return out, nil
+}
+
+// A DerivationCheck is a test on the output of [Derivation]. The functions
+// which make a DerivationCheck consider the steps at the current group level,
+// which are the steps before the next group. Use [InContextGroup] to move into
+// a group.
+type DerivationCheck func([]DerivationStep) error
+
+// ContextAttrs makes a DerivationCheck which runs the input Checks on the
+// attributes from the steps at the current group level, and then combines
+// non-nil errors into 1 using [errors.Join]. Attribute values are resolved.
+func ContextAttrs(c Check, moreChecks ...Check) DerivationCheck {
+	return func(steps []DerivationStep) error {
+		var attrs []slog.Attr
+		for _, step := range steps {
+			if step.Group != "" {
+				break
+			}
+			for _, a := range step.Attrs {
+				a.Value = a.Value.Resolve()
+				attrs = append(attrs, a)
+			}
+		}
+
+		errs := make([]error, 0, 1+len(moreChecks))
+		for _, check := range append([]Check{c}, moreChecks...) {
+			if err := check(attrs); err != nil {
+				errs = append(errs, err)
+			}
+		}
+		return errors.Join(errs...)
+	}
+}
+
+// HasContextAttr makes a DerivationCheck for the presence of an attribute with
+// the wanted key and value, added with WithAttrs at the current group level.
+// It's like [HasAttr] for the logger's context rather than a record.
+func HasContextAttr(want slog.Attr) DerivationCheck {
+	return ContextAttrs(HasAttr(want))
+}
+
+// InContextGroup makes a DerivationCheck for a DerivationCheck in a group with a
+// matching name. The group must be the next group, opened with WithGroup, at
+// the current level. The input checks are run on the steps after the group,
+// and then non-nil errors are combined into 1 using [errors.Join]. Like
+// [InGroup], each error has the path to the group.
+func InContextGroup(name string, c DerivationCheck, moreChecks ...DerivationCheck) DerivationCheck {
+	return func(steps []DerivationStep) error {
+		ind := slices.IndexFunc(steps, func(step DerivationStep) bool { return step.Group != "" })
+		if ind < 0 {
+			err := fmt.Errorf("looking for context group with name %s: no more groups", name)
+			return errors.Join(err)
+		}
+		if got := steps[ind].Group; got != name {
+			err := fmt.Errorf("looking for context group with name %s: next group is %s", name, got)
+			return errors.Join(err)
+		}
+
+		errs := make([]error, 0, 1+len(moreChecks))
+		rest := steps[ind+1:]
+		for _, check := range append([]DerivationCheck{c}, moreChecks...) {
+			if err := check(rest); err != nil {
+				err = makeErrorWithGroupPath(err, name)
+				errs = append(errs, err)
+			}
+		}
+		errs = slices.Clip(errs)
+		return errors.Join(errs...)
+	}
+}
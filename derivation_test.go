@@ -0,0 +1,133 @@
+package slogtesting_test
+
+import (
+	"io"
+	"log/slog"
+	"slices"
+	"testing"
+
+	st "github.com/rafaelespinoza/slogtesting"
+)
+
+// tokenValuer is a slog.LogValuer, for checking that values are resolved.
+type tokenValuer struct{}
+
+func (tokenValuer) LogValue() slog.Value { return slog.StringValue("REDACTED") }
+
+func TestDerivation(t *testing.T) {
+	rec := st.NewRecorder(nil)
+	logger := slog.New(rec.Handler()).
+		With("service", "api").
+		With("token", tokenValuer{}).
+		WithGroup("request").
+		With("request_id", "abc").
+		WithGroup("component").
+		With("name", "db")
+
+	t.Run("steps", func(t *testing.T) {
+		steps, err := st.Derivation(logger.Handler())
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		expGroups := []string{"", "", "request", "", "component", ""}
+		gotGroups := make([]string, len(steps))
+		for i, step := range steps {
+			gotGroups[i] = step.Group
+		}
+		if !slices.Equal(gotGroups, expGroups) {
+			t.Errorf("wrong groups\ngot: %q\nexp: %q", gotGroups, expGroups)
+		}
+		if !steps[3].Attrs[0].Equal(slog.String("request_id", "abc")) {
+			t.Errorf("wrong attrs; got %v", steps[3].Attrs)
+		}
+	})
+
+	t.Run("no records needed", func(t *testing.T) {
+		if got := rec.Len(); got != 0 {
+			t.Errorf("expected no records; got %d", got)
+		}
+	})
+
+	t.Run("router handler", func(t *testing.T) {
+		h := st.NewRouter(nil).Handler().WithGroup("G").WithAttrs([]slog.Attr{slog.Int("a", 1)})
+		steps, err := st.Derivation(h)
+		if err != nil {
+			t.Fatal(err)
+		}
+		requireResultLen(t, steps, 2)
+	})
+
+	t.Run("unsupported", func(t *testing.T) {
+		_, err := st.Derivation(slog.NewTextHandler(io.Discard, nil))
+		if err == nil {
+			t.Fatal("expected an error but got nil")
+		}
+		t.Log(err)
+	})
+
+	steps, err := st.Derivation(logger.Handler())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	t.Run("checks", func(t *testing.T) {
+		checks := []st.DerivationCheck{
+			st.HasContextAttr(slog.String("service", "api")),
+			st.HasContextAttr(slog.String("token", "REDACTED")),
+			st.ContextAttrs(st.MissingKey("request_id"), st.HasKey("service")),
+			st.InContextGroup("request",
+				st.HasContextAttr(slog.String("request_id", "abc")),
+				st.ContextAttrs(st.MissingKey("name")),
+				st.InContextGroup("component", st.HasContextAttr(slog.String("name", "db"))),
+			),
+		}
+		for i, check := range checks {
+			if err := check(steps); err != nil {
+				t.Errorf("check[%d] %v", i, err)
+			}
+		}
+	})
+
+	t.Run("failures", func(t *testing.T) {
+		type errorWithGroupPath interface{ GroupPath() []string }
+
+		tests := []struct {
+			name         string
+			check        st.DerivationCheck
+			expGroupPath []string
+		}{
+			{name: "attr in wrong group", check: st.HasContextAttr(slog.String("request_id", "abc"))},
+			{name: "wrong value", check: st.HasContextAttr(slog.String("service", "web"))},
+			{name: "wrong group", check: st.InContextGroup("component", st.HasContextAttr(slog.String("name", "db")))},
+			{name: "no more groups", check: st.InContextGroup("request", st.InContextGroup("component", st.InContextGroup("x", st.ContextAttrs(st.HasKey("y")))))},
+			{
+				name:         "nested",
+				check:        st.InContextGroup("request", st.InContextGroup("component", st.HasContextAttr(slog.String("name", "cache")))),
+				expGroupPath: []string{"request", "component"},
+			},
+		}
+
+		for _, test := range tests {
+			t.Run(test.name, func(t *testing.T) {
+				err := test.check(steps)
+				if err == nil {
+					t.Fatal("expected an error but got nil")
+				}
+				t.Log(err)
+
+				if test.expGroupPath == nil {
+					return
+				}
+				errs := err.(interface{ Unwrap() []error }).Unwrap()
+				withPath, ok := errs[0].(errorWithGroupPath)
+				if !ok {
+					t.Fatal("expected error to implement expected interface with GroupPath method")
+				}
+				if got := withPath.GroupPath(); !slices.Equal(got, test.expGroupPath) {
+					t.Errorf("group path wrong; got %q, expected %q", got, test.expGroupPath)
+				}
+			})
+		}
+	})
+}
@@ -0,0 +1,37 @@
+package slogtesting
+
+import "errors"
+
+// A DuplicateKeyPolicy decides what the handler does with an attribute whose
+// key is already used by another attribute at the same level, either at the
+// top level of a record or within the same group. The policy is applied at
+// every level of nested groups. Groups opened with [slog.Logger.WithGroup]
+// are never duplicates of themselves. See the DuplicateKeys field of
+// [AttrHandlerOptions].
+type DuplicateKeyPolicy int
+
+const (
+	// DuplicateMergeGroups merges groups with the same key into 1 group, and
+	// keeps each non-group attribute, even one after a group with the same
+	// key. A group after a non-group attribute with the same key cannot be
+	// placed, so it's dropped. This is the default.
+	DuplicateMergeGroups DuplicateKeyPolicy = iota
+	// DuplicateKeepAll keeps every attribute, including each group, in the
+	// order they were added. This is similar to the output of a
+	// [slog.JSONHandler].
+	DuplicateKeepAll
+	// DuplicateFirstWins merges groups with the same key, and otherwise keeps
+	// the first attribute with a key.
+	DuplicateFirstWins
+	// DuplicateLastWins merges groups with the same key, and otherwise keeps
+	// the last attribute with a key, in the position of the first one.
+	DuplicateLastWins
+	// DuplicateError places attributes like DuplicateMergeGroups, but the
+	// handler's Handle method also returns an error wrapping ErrDuplicateKey.
+	// The record is captured anyways.
+	DuplicateError
+)
+
+// ErrDuplicateKey is reported when a record has duplicate keys and the
+// policy is [DuplicateError].
+var ErrDuplicateKey = errors.New(logPrefix + "duplicate key")
@@ -0,0 +1,69 @@
+package slogtesting
+
+import (
+	"bytes"
+	"log/slog"
+	"runtime"
+	"slices"
+	"strconv"
+	"time"
+)
+
+// An Envelope is a captured record along with metadata about its capture. When
+// many goroutines log concurrently, the metadata helps to reason about how the
+// records were interleaved. See [Recorder.Envelopes].
+type Envelope struct {
+	Record slog.Record
+	// Seq is a sequence number which starts at 1 and increases with each
+	// capture. It's shared by a handler and the handlers derived from it, so
+	// sorting by Seq puts records in the order they were captured.
+	Seq uint64
+	// GoroutineID identifies the goroutine which called the handler's Handle
+	// method. It's 0 if the ID could not be determined.
+	GoroutineID uint64
+	// Received is when the handler's Handle method was called. It's unrelated
+	// to the time of the record.
+	Received time.Time
+	// Provenance is where each attribute came from. It's empty unless the
+	// TrackProvenance field of [AttrHandlerOptions] is true.
+	Provenance Provenance
+	// Conflicts lists the attributes that were dropped from the record because
+	// of a conflict between a group and an earlier non-group attribute.
+	Conflicts []Conflict
+}
+
+// An EnvelopeCheck is a test on a captured [Envelope].
+type EnvelopeCheck func(Envelope) error
+
+// clone makes a copy of e which does not share the memory of the record or of
+// the conflicts.
+func (e Envelope) clone() Envelope {
+	e.Record = e.Record.Clone()
+	if e.Conflicts != nil {
+		conflicts := make([]Conflict, len(e.Conflicts))
+		for i, c := range e.Conflicts {
+			c.Path = slices.Clone(c.Path)
+			c.ConflictsWith = slices.Clone(c.ConflictsWith)
+			conflicts[i] = c
+		}
+		e.Conflicts = conflicts
+	}
+	return e
+}
+
+// goroutineID parses the ID of the calling goroutine from its stack trace. The
+// runtime does not otherwise expose it.
+func goroutineID() uint64 {
+	var buf [64]byte
+	n := runtime.Stack(buf[:], false)
+
+	// The output starts like "goroutine 123 [running]:".
+	field := bytes.TrimPrefix(buf[:n], []byte("goroutine "))
+	if i := bytes.IndexByte(field, ' '); i > 0 {
+		id, err := strconv.ParseUint(string(field[:i]), 10, 64)
+		if err == nil {
+			return id
+		}
+	}
+	return 0
+}
@@ -0,0 +1,119 @@
+package slogtesting_test
+
+import (
+	"log/slog"
+	"slices"
+	"sync"
+	"testing"
+	"time"
+
+	st "github.com/rafaelespinoza/slogtesting"
+)
+
+func TestRecorderEnvelopes(t *testing.T) {
+	const numGoroutines, numRecords = 4, 25
+
+	rec := st.NewRecorder(&st.AttrHandlerOptions{TrackProvenance: true})
+	logger := slog.New(rec.Handler())
+
+	var wg sync.WaitGroup
+	for i := range numGoroutines {
+		// Derived handlers share the sequence.
+		lgr := logger.With("goroutine", i)
+		wg.Go(func() {
+			for j := range numRecords {
+				lgr.Info("msg", "j", j)
+			}
+		})
+	}
+	wg.Wait()
+
+	envelopes := rec.Envelopes()
+	requireResultLen(t, envelopes, numGoroutines*numRecords)
+
+	goroutineIDs := make(map[uint64]int)
+	for i, env := range envelopes {
+		if env.Seq != uint64(i+1) {
+			t.Errorf("envelope[%d] wrong Seq; got %d, expected %d", i, env.Seq, i+1)
+		}
+		// Handle may be called concurrently, so the receive times are not
+		// guaranteed to be in order. Only check that they're set.
+		if env.Received.IsZero() {
+			t.Errorf("envelope[%d] expected non-zero Received", i)
+		}
+		if env.GoroutineID == 0 {
+			t.Errorf("envelope[%d] expected non-zero GoroutineID", i)
+		}
+		if got := env.Provenance.Origin("goroutine"); got != st.OriginLoggerContext {
+			t.Errorf("envelope[%d] wrong origin; got %s", i, got)
+		}
+		goroutineIDs[env.GoroutineID]++
+	}
+
+	if len(goroutineIDs) != numGoroutines {
+		t.Errorf("wrong number of goroutine IDs; got %d, expected %d", len(goroutineIDs), numGoroutines)
+	}
+	for id, n := range goroutineIDs {
+		if n != numRecords {
+			t.Errorf("wrong number of records for goroutine %d; got %d, expected %d", id, n, numRecords)
+		}
+	}
+
+	// Each goroutine's records are in its own order.
+	perGoroutine := make(map[uint64][]int64)
+	for _, env := range envelopes {
+		attrs := st.GetRecordAttrs(env.Record)
+		j := attrs[slices.IndexFunc(attrs, func(a slog.Attr) bool { return a.Key == "j" })]
+		perGoroutine[env.GoroutineID] = append(perGoroutine[env.GoroutineID], j.Value.Int64())
+	}
+	for id, js := range perGoroutine {
+		if !slices.IsSorted(js) {
+			t.Errorf("records for goroutine %d out of order: %v", id, js)
+		}
+	}
+
+	// The output is a copy.
+	envelopes[0].Record.AddAttrs(slog.String("extra", "x"))
+	if err := st.MissingKey("extra")(st.GetRecordAttrs(rec.Envelopes()[0].Record)); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestRecorderEnvelopesReceivedInTeeMode(t *testing.T) {
+	// Next is slow. Its latency should not show in the receive time.
+	var nextStarted time.Time
+	next := st.NewAttrHandler(&st.AttrHandlerOptions{CaptureRecord: func(slog.Record) error {
+		nextStarted = time.Now()
+		time.Sleep(20 * time.Millisecond)
+		return nil
+	}})
+	rec := st.NewRecorder(&st.AttrHandlerOptions{Next: next})
+	slog.New(rec.Handler()).Info("msg")
+
+	envelopes := rec.Envelopes()
+	requireResultLen(t, envelopes, 1)
+	if got := envelopes[0].Received; got.After(nextStarted) {
+		t.Errorf("expected Received (%v) to be before Next handled the record (%v)", got, nextStarted)
+	}
+}
+
+func TestRecorderEnvelopesConflicts(t *testing.T) {
+	rec := st.NewRecorder(nil)
+	slog.New(rec.Handler()).With("G", 1).WithGroup("G").Info("msg", "a", 2)
+
+	envelopes := rec.Envelopes()
+	requireResultLen(t, envelopes, 1)
+	requireResultLen(t, envelopes[0].Conflicts, 1)
+
+	// The output is a copy, down to the paths of each conflict.
+	envelopes[0].Conflicts[0].Path[0] = "changed"
+	envelopes[0].Conflicts[0].ConflictsWith[0] = "changed"
+	envelopes[0].Conflicts[0] = st.Conflict{}
+
+	got := rec.Envelopes()[0].Conflicts[0]
+	expected := st.Conflict{Path: []string{"G", "a"}, Value: slog.IntValue(2), Origin: st.OriginCallSite, ConflictsWith: []string{"G"}}
+	if !slices.Equal(got.Path, expected.Path) || !slices.Equal(got.ConflictsWith, expected.ConflictsWith) ||
+		!got.Value.Equal(expected.Value) || got.Origin != expected.Origin {
+		t.Errorf("wrong conflict; got %+v, expected %+v", got, expected)
+	}
+}
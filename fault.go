@@ -0,0 +1,95 @@
+package slogtesting
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"time"
+)
+
+// A Fault is a failure for the handler's Handle method to simulate, so that
+// code which reacts to logging failures can be tested deterministically. See
+// the Faults field of [AttrHandlerOptions]. A Fault applies to a call to Handle
+// when all of its conditions, Every and Match, are met. If it has neither
+// condition, then it applies to every call.
+//
+// Faults are applied after the record is captured, so captures still show
+// what was attempted. A Fault waits for Delay, then panics with Panic if it's
+// non-nil, otherwise Handle returns Err.
+type Fault struct {
+	// Every, if positive, is a condition that the call is the Nth call, or a
+	// multiple of it, to Handle. Calls are counted across the handler and the
+	// handlers derived from it.
+	Every int
+	// Match, if non-nil, is a condition that the attributes of the record, as
+	// captured, pass the Check. They include the time as normalized by the
+	// Clock, UTC and TruncateTime fields of AttrHandlerOptions. A record which
+	// is not captured, such as one below the level in tee mode, is built the
+	// same way for the Check, except that the Clock is not called, so that the
+	// times of captured records don't depend on it. So its time is only
+	// normalized by the UTC and TruncateTime fields.
+	Match Check
+
+	// Delay is how long to wait before failing. The wait stops early if the
+	// context passed to Handle is done.
+	Delay time.Duration
+	// Panic, if non-nil, is the value passed to panic.
+	Panic any
+	// Err is returned from Handle.
+	Err error
+}
+
+func (f *Fault) applies(numCall uint64, attrs func() []slog.Attr) bool {
+	if f.Every > 0 && numCall%uint64(f.Every) != 0 {
+		return false
+	}
+	if f.Match != nil && f.Match(attrs()) != nil {
+		return false
+	}
+	return true
+}
+
+// injectFaults applies the handler's faults for the numCall-th call to Handle.
+// The captured Envelope is the output of capturing rec. If it's nil, then rec
+// is built only if a Fault needs its attributes. That build is only a view for
+// the Check, so it's not traced by Diagnostics.
+func (h *attrHandler) injectFaults(ctx context.Context, numCall uint64, rec slog.Record, captured *Envelope) error {
+	var attrs []slog.Attr
+	if captured != nil {
+		attrs = GetRecordAttrs(captured.Record)
+	}
+	getAttrs := func() []slog.Attr {
+		if attrs == nil {
+			view := *h
+			view.opts.Diagnostics = nil
+			rec.Time = h.normalizeTime(rec.Time)
+			built, _ := view.buildRecordAttrs(rec)
+			attrs = GetRecordAttrs(built.Record)
+		}
+		return attrs
+	}
+
+	var errs []error
+	for i := range h.opts.Faults {
+		fault := &h.opts.Faults[i]
+		if !fault.applies(numCall, getAttrs) {
+			continue
+		}
+
+		if fault.Delay > 0 {
+			timer := time.NewTimer(fault.Delay)
+			select {
+			case <-timer.C:
+			case <-ctx.Done():
+				timer.Stop()
+			}
+		}
+		if fault.Panic != nil {
+			panic(fault.Panic)
+		}
+		if fault.Err != nil {
+			errs = append(errs, fault.Err)
+		}
+	}
+	return errors.Join(errs...)
+}
@@ -0,0 +1,181 @@
+package slogtesting_test
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"testing"
+	"time"
+
+	st "github.com/rafaelespinoza/slogtesting"
+)
+
+func TestFaults(t *testing.T) {
+	testErr := errors.New("test")
+
+	handle := func(h slog.Handler, msg string, args ...any) error {
+		r := slog.NewRecord(time.Now(), slog.LevelInfo, msg, 0)
+		r.Add(args...)
+		return h.Handle(context.Background(), r)
+	}
+
+	t.Run("every", func(t *testing.T) {
+		rec := st.NewRecorder(&st.AttrHandlerOptions{
+			Faults: []st.Fault{{Every: 2, Err: testErr}},
+		})
+		// Calls are counted across derived handlers.
+		handlers := []slog.Handler{rec.Handler(), rec.Handler().WithGroup("G")}
+
+		var gotErrs []bool
+		for i := range 5 {
+			err := handle(handlers[i%2], "msg")
+			if err != nil && !errors.Is(err, testErr) {
+				t.Fatalf("wrong error; got %v, expected %v", err, testErr)
+			}
+			gotErrs = append(gotErrs, err != nil)
+		}
+
+		expErrs := []bool{false, true, false, true, false}
+		for i, got := range gotErrs {
+			if got != expErrs[i] {
+				t.Errorf("call[%d] wrong error status; got %t, expected %t", i, got, expErrs[i])
+			}
+		}
+		// Every attempt is captured.
+		requireResultLen(t, rec.Records(), 5)
+	})
+
+	t.Run("match", func(t *testing.T) {
+		rec := st.NewRecorder(&st.AttrHandlerOptions{
+			Faults: []st.Fault{{Match: st.HasAttr(slog.Bool("fail", true)), Err: testErr}},
+		})
+		if err := handle(rec.Handler(), "msg", "fail", false); err != nil {
+			t.Errorf("unexpected error %v", err)
+		}
+		if err := handle(rec.Handler(), "msg", "fail", true); !errors.Is(err, testErr) {
+			t.Errorf("wrong error; got %v, expected %v", err, testErr)
+		}
+		requireResultLen(t, rec.Records(), 2)
+	})
+
+	t.Run("match normalized time", func(t *testing.T) {
+		start := time.Date(2025, 11, 1, 12, 0, 0, 0, time.UTC)
+		fault := st.Fault{Match: st.HasAttr(slog.Time(slog.TimeKey, start)), Err: testErr}
+
+		// The record is built for the Fault whether or not it's captured. Only a
+		// captured record gets its time from the Clock.
+		handlers := map[string]slog.Handler{
+			"captured": st.NewRecorder(&st.AttrHandlerOptions{
+				Clock:  st.FixedClock(start),
+				Faults: []st.Fault{fault},
+			}).Handler(),
+			"not captured": st.NewAttrHandler(&st.AttrHandlerOptions{
+				UTC:          true,
+				TruncateTime: time.Minute,
+				Faults:       []st.Fault{fault},
+			}),
+		}
+		for name, h := range handlers {
+			r := slog.NewRecord(start.Add(time.Second).In(time.FixedZone("X", 3600)), slog.LevelInfo, "msg", 0)
+			if err := h.Handle(context.Background(), r); !errors.Is(err, testErr) {
+				t.Errorf("%s: wrong error; got %v, expected %v", name, err, testErr)
+			}
+		}
+	})
+
+	t.Run("match does not call clock for record not captured", func(t *testing.T) {
+		start := time.Date(2025, 11, 1, 12, 0, 0, 0, time.UTC)
+		diagnostics := st.NewRecorder(&st.AttrHandlerOptions{HandlerOptions: slog.HandlerOptions{Level: slog.LevelDebug}})
+		rec := st.NewRecorder(&st.AttrHandlerOptions{
+			Clock:       st.StepClock(start, time.Second),
+			Diagnostics: diagnostics.Handler(),
+			Next:        slog.NewTextHandler(io.Discard, &slog.HandlerOptions{Level: slog.LevelDebug}),
+			Faults:      []st.Fault{{Match: st.HasKey("fail"), Err: testErr}},
+		})
+		logger := slog.New(rec.Handler())
+		logger.Info("a")
+		logger.Debug("b", "fail", true)
+		logger.Info("c")
+
+		records := rec.Records()
+		requireResultLen(t, records, 2)
+		for i, expected := range []time.Time{start, start.Add(time.Second)} {
+			if got := records[i].Time; !got.Equal(expected) {
+				t.Errorf("record[%d] wrong time; got %v, expected %v", i, got, expected)
+			}
+		}
+		for _, r := range diagnostics.Records() {
+			if st.HasAttr(slog.String("path", "fail"))(st.GetRecordAttrs(r)) == nil {
+				t.Errorf("unexpected trace for a record which is not captured: %v", r)
+			}
+		}
+	})
+
+	t.Run("match builds record once", func(t *testing.T) {
+		diagnostics := st.NewRecorder(&st.AttrHandlerOptions{HandlerOptions: slog.HandlerOptions{Level: slog.LevelDebug}})
+		rec := st.NewRecorder(&st.AttrHandlerOptions{
+			Diagnostics: diagnostics.Handler(),
+			Faults:      []st.Fault{{Match: st.HasKey("a"), Err: testErr}},
+		})
+		if err := handle(rec.Handler(), "msg", "a", 1); !errors.Is(err, testErr) {
+			t.Errorf("wrong error; got %v, expected %v", err, testErr)
+		}
+
+		var numTraces int
+		for _, r := range diagnostics.Records() {
+			if st.HasAttr(slog.String("path", "a"))(st.GetRecordAttrs(r)) == nil {
+				numTraces++
+			}
+		}
+		if numTraces != 1 {
+			t.Errorf("wrong number of traces for attribute a; got %d, expected %d", numTraces, 1)
+		}
+	})
+
+	t.Run("every and match", func(t *testing.T) {
+		h := st.NewAttrHandler(&st.AttrHandlerOptions{
+			Faults: []st.Fault{{Every: 2, Match: st.HasKey("fail"), Err: testErr}},
+		})
+		expErrs := []bool{false, false, false, true}
+		for i, args := range [][]any{{"fail", 1}, {"ok", 1}, {"fail", 1}, {"fail", 1}} {
+			err := handle(h, "msg", args...)
+			if (err != nil) != expErrs[i] {
+				t.Errorf("call[%d] wrong error status; got %v", i, err)
+			}
+		}
+	})
+
+	t.Run("delay", func(t *testing.T) {
+		const delay = 20 * time.Millisecond
+		h := st.NewAttrHandler(&st.AttrHandlerOptions{Faults: []st.Fault{{Delay: delay}}})
+
+		start := time.Now()
+		if err := handle(h, "msg"); err != nil {
+			t.Errorf("unexpected error %v", err)
+		}
+		if elapsed := time.Since(start); elapsed < delay {
+			t.Errorf("expected a delay of at least %s; got %s", delay, elapsed)
+		}
+
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+		h = st.NewAttrHandler(&st.AttrHandlerOptions{Faults: []st.Fault{{Delay: time.Hour}}})
+		if err := h.Handle(ctx, slog.NewRecord(time.Now(), slog.LevelInfo, "msg", 0)); err != nil {
+			t.Errorf("unexpected error %v", err)
+		}
+	})
+
+	t.Run("panic", func(t *testing.T) {
+		rec := st.NewRecorder(&st.AttrHandlerOptions{Faults: []st.Fault{{Panic: "boom"}}})
+		func() {
+			defer func() {
+				if got := recover(); got != "boom" {
+					t.Errorf("wrong panic value; got %v, expected %v", got, "boom")
+				}
+			}()
+			slog.New(rec.Handler()).Info("msg")
+		}()
+		requireResultLen(t, rec.Records(), 1)
+	})
+}
@@ -0,0 +1,80 @@
+package slogtesting
+
+import (
+	"fmt"
+	"log/slog"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// formatRecord renders a record on 1 line, for use in error messages. It looks
+// similar to the output of a [slog.TextHandler]; attributes in groups have
+// dotted keys. The time is left out because it's rarely helpful in an error
+// message, and the builtin attributes of a record built by this package's
+// handler are not repeated.
+func formatRecord(r slog.Record) string {
+	var sb strings.Builder
+	sb.WriteString("level=" + r.Level.String())
+	sb.WriteString(" msg=" + formatString(r.Message))
+
+	r.Attrs(func(a slog.Attr) bool {
+		switch a.Key {
+		case slog.TimeKey, slog.LevelKey, slog.MessageKey:
+			return true
+		}
+		writeAttr(&sb, "", a)
+		return true
+	})
+	return sb.String()
+}
+
+func writeAttr(sb *strings.Builder, prefix string, a slog.Attr) {
+	a.Value = a.Value.Resolve()
+	if a.Value.Kind() == slog.KindGroup {
+		if a.Key != "" {
+			prefix += a.Key + "."
+		}
+		for _, ga := range a.Value.Group() {
+			writeAttr(sb, prefix, ga)
+		}
+		return
+	}
+
+	sb.WriteString(" " + prefix + a.Key + "=" + formatValue(a.Value))
+}
+
+func formatValue(v slog.Value) string {
+	switch v.Kind() {
+	case slog.KindString:
+		return formatString(v.String())
+	case slog.KindTime:
+		return v.Time().Format(time.RFC3339Nano)
+	case slog.KindAny:
+		switch val := v.Any().(type) {
+		case *slog.Source:
+			return formatSource(val)
+		case slog.Source:
+			return formatSource(&val)
+		case error:
+			return formatString(val.Error())
+		}
+		return formatString(fmt.Sprint(v.Any()))
+	default:
+		return v.String()
+	}
+}
+
+func formatSource(src *slog.Source) string {
+	if src == nil {
+		return "<nil>"
+	}
+	return src.File + ":" + strconv.Itoa(src.Line)
+}
+
+func formatString(s string) string {
+	if s == "" || strings.ContainsAny(s, " =\"\t\r\n") || !strconv.CanBackquote(s) {
+		return strconv.Quote(s)
+	}
+	return s
+}
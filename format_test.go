@@ -0,0 +1,63 @@
+package slogtesting
+
+import (
+	"errors"
+	"log/slog"
+	"testing"
+	"time"
+)
+
+func TestFormatRecord(t *testing.T) {
+	tests := []struct {
+		name  string
+		attrs []slog.Attr
+		exp   string
+	}{
+		{
+			name: "empty",
+			exp:  `level=INFO msg="hello world"`,
+		},
+		{
+			name: "builtins are not repeated",
+			attrs: []slog.Attr{
+				slog.Time(slog.TimeKey, time.Now()),
+				slog.String(slog.LevelKey, "INFO"),
+				slog.String(slog.MessageKey, "hello world"),
+			},
+			exp: `level=INFO msg="hello world"`,
+		},
+		{
+			name: "kinds",
+			attrs: []slog.Attr{
+				slog.String("s", "x"),
+				slog.String("empty", ""),
+				slog.Int("i", -1),
+				slog.Bool("b", true),
+				slog.Duration("d", time.Second),
+				slog.Time("t", time.Date(2006, 1, 2, 15, 4, 5, 0, time.UTC)),
+				slog.Any("err", errors.New("oh no")),
+				slog.Any(slog.SourceKey, &slog.Source{File: "/src/file.go", Line: 10}),
+			},
+			exp: `level=INFO msg="hello world" s=x empty="" i=-1 b=true d=1s t=2006-01-02T15:04:05Z err="oh no" source=/src/file.go:10`,
+		},
+		{
+			name: "groups",
+			attrs: []slog.Attr{
+				slog.GroupAttrs("G", slog.String("c", "d"), slog.GroupAttrs("H", slog.String("e", "f"))),
+				slog.GroupAttrs("", slog.String("inline", "y")),
+			},
+			exp: `level=INFO msg="hello world" G.c=d G.H.e=f inline=y`,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			r := slog.NewRecord(time.Now(), slog.LevelInfo, "hello world", 0)
+			r.AddAttrs(test.attrs...)
+			got := formatRecord(r)
+			if got != test.exp {
+				t.Errorf("wrong output\ngot: %s\nexp: %s", got, test.exp)
+			}
+		})
+	}
+}
@@ -36,3 +36,18 @@ func applyGroupsOrAttrs(g *groupsOrAttrs, f func(groups []string, a slog.Attr))
 
 	return groups
 }
+
+// replayGroupsOrAttrs derives a handler from h by calling its WithGroup or
+// WithAttrs method for each item in g, starting with the oldest item. Use this
+// when the destination handler is only known after accumulating data.
+func replayGroupsOrAttrs(h slog.Handler, g *groupsOrAttrs) slog.Handler {
+	if g == nil {
+		return h
+	}
+
+	h = replayGroupsOrAttrs(h, g.Next)
+	if g.Group != "" {
+		return h.WithGroup(g.Group)
+	}
+	return h.WithAttrs(g.Attrs)
+}
@@ -119,3 +119,31 @@ func TestApplyGroupsOrAttrs(t *testing.T) {
 		})
 	}
 }
+
+func TestReplayGroupsOrAttrs(t *testing.T) {
+	var got []slog.Record
+	root := NewAttrHandler(&AttrHandlerOptions{
+		CaptureRecord: func(r slog.Record) error {
+			got = append(got, r)
+			return nil
+		},
+	})
+
+	goas := &groupsOrAttrs{Attrs: []slog.Attr{slog.String("c", "d")}, Next: &groupsOrAttrs{
+		Group: "G", Next: &groupsOrAttrs{Attrs: []slog.Attr{slog.String("a", "b")}},
+	}}
+	handler := replayGroupsOrAttrs(root, goas)
+	slog.New(handler).Info("msg", "e", "f")
+	slog.New(root).With("a", "b").WithGroup("G").With("c", "d").Info("msg", "e", "f")
+
+	if len(got) != 2 {
+		t.Fatalf("wrong number of records; got %d, expected %d", len(got), 2)
+	}
+	gotAttrs, expAttrs := GetRecordAttrs(got[0])[3:], GetRecordAttrs(got[1])[3:]
+	if !slices.EqualFunc(gotAttrs, expAttrs, slog.Attr.Equal) {
+		t.Errorf("unexpected output\ngot: %q\nexp: %q", gotAttrs, expAttrs)
+	}
+	if replayGroupsOrAttrs(root, nil) != root {
+		t.Error("expected input handler for empty groupsOrAttrs")
+	}
+}
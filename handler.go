@@ -2,23 +2,96 @@ package slogtesting
 
 import (
 	"context"
+	"errors"
 	"log/slog"
-	"slices"
 	"sync"
+	"sync/atomic"
+	"time"
 )
 
 type attrHandler struct {
 	opts AttrHandlerOptions
 	mtx  *sync.Mutex
-	goas *groupsOrAttrs
+	// numCalls counts calls to Handle across derived handlers.
+	numCalls *atomic.Uint64
+	goas     *groupsOrAttrs
+	next     slog.Handler
+	// seq is the sequence number of the last capture, shared across derived
+	// handlers. Use it while holding mtx.
+	seq *uint64
+	// sink, if non-empty, receives everything about a captured record. It's
+	// for use within this package, such as by a Recorder. It's called while
+	// holding mtx, so it must not block.
+	sink func(Envelope) error
 }
 
 // AttrHandlerOptions is a superset of [slog.HandlerOptions] for use in
-// [NewAttrHandler]. The CaptureRecord field is a callback function for using a
-// record processed by the handler's Handle method.
+// [NewAttrHandler].
+//
+// If the AddSource field of the embedded slog.HandlerOptions is true, then
+// output records will have an attribute with the key [slog.SourceKey] and a
+// *[slog.Source] value, which is passed through ReplaceAttr like any other
+// builtin attribute.
 type AttrHandlerOptions struct {
 	slog.HandlerOptions
+
+	// CaptureRecord is a callback function for using a record processed by the
+	// handler's Handle method.
 	CaptureRecord func(r slog.Record) error
+
+	// CaptureShadow, if non-empty, is called when the handler's Enabled method
+	// returns false because of the Level field. It's for asserting that code
+	// would log at a level which the handler is not configured for. With Next,
+	// a call is only rejected if Next also rejects it. Finding the call site
+	// has a cost, so only use this when needed.
+	CaptureShadow func(s ShadowRecord)
+
+	// TrackProvenance enables building a [Provenance] index for each captured
+	// record. It's available via [Recorder.Provenances].
+	TrackProvenance bool
+
+	// Next enables a pass-through, or tee, mode. When it's non-empty, the
+	// handler forwards each call to Enabled, Handle, WithAttrs and WithGroup
+	// onto Next while also capturing records. This keeps the formatted output
+	// of another handler, such as a [slog.JSONHandler], while still allowing
+	// tests to inspect golang data structures. The Level field only applies to
+	// captures; Next decides for itself which records it handles.
+	Next slog.Handler
+
+	// Faults are failures for the Handle method to simulate. See [Fault].
+	Faults []Fault
+
+	// DuplicateKeys decides what happens to an attribute whose key is already
+	// used at the same level of the record. The default is
+	// [DuplicateMergeGroups].
+	DuplicateKeys DuplicateKeyPolicy
+
+	// ErrorOnConflict makes the Handle method return an error wrapping
+	// [ErrConflict] when an attribute is dropped because of a [Conflict]. The
+	// record is captured anyways. Regardless of this field, conflicts are
+	// available via the Conflicts field of [Envelope].
+	ErrorOnConflict bool
+
+	// Diagnostics, if non-nil, receives a trace of how the handler places each
+	// attribute into a captured record. It's for debugging the normalization
+	// of records, such as an attribute that ends up in an unexpected group.
+	// Each placement decision is logged at [slog.LevelDebug] with the message
+	// "slogtesting: placement" and the attributes decision, path, kind, origin
+	// and, for a [Conflict], conflicts_with. Paths are dotted strings. The
+	// default is no tracing.
+	Diagnostics slog.Handler
+
+	// Clock, if non-nil, replaces the time of each captured record, so that
+	// captures are reproducible. See [FixedClock] and [StepClock]. Records
+	// without a time keep the zero time. Like the UTC and TruncateTime fields,
+	// it's applied before ReplaceAttr, and does not affect the records passed
+	// to Next. These fields also apply to the time of a [ShadowRecord].
+	Clock Clock
+	// UTC converts the time of each captured record to UTC.
+	UTC bool
+	// TruncateTime, if positive, rounds down the time of each captured record
+	// to a multiple of it, as with [time.Time.Truncate].
+	TruncateTime time.Duration
 }
 
 // NewAttrHandler creates a [slog.Handler] that outputs attributes without any
@@ -33,18 +106,43 @@ type AttrHandlerOptions struct {
 //
 // If all you need is to run a test involving some logging action and to inspect
 // the logging ouput, then [CaptureRecords] might suit that need more directly.
-// For other use cases, this handler is available.
+// To keep records across many steps of a test, see [Recorder]. For other use
+// cases, this handler is available.
 func NewAttrHandler(opts *AttrHandlerOptions) slog.Handler {
+	return newAttrHandler(opts)
+}
+
+func newAttrHandler(opts *AttrHandlerOptions) *attrHandler {
 	if opts == nil {
 		opts = &AttrHandlerOptions{}
 	}
 	return &attrHandler{
-		opts: *opts,
-		mtx:  &sync.Mutex{},
+		opts:     *opts,
+		mtx:      &sync.Mutex{},
+		numCalls: &atomic.Uint64{},
+		seq:      new(uint64),
+		next:     opts.Next,
+	}
+}
+
+func (h *attrHandler) Enabled(ctx context.Context, lvl slog.Level) bool {
+	if h.captureEnabled(lvl) {
+		return true
 	}
+	// In tee mode, a call accepted by Next is not a shadow, even though it
+	// won't be captured.
+	if h.next != nil && h.next.Enabled(ctx, lvl) {
+		return true
+	}
+	if capture := h.opts.CaptureShadow; capture != nil {
+		capture(ShadowRecord{Time: h.recordTime(time.Now()), Level: lvl, Source: callerSource()})
+	}
+	return false
 }
 
-func (h *attrHandler) Enabled(_ context.Context, lvl slog.Level) bool {
+// captureEnabled reports whether or not the handler's own level allows for
+// capturing a record at the input level.
+func (h *attrHandler) captureEnabled(lvl slog.Level) bool {
 	level := slog.LevelInfo
 	if h.opts.Level != nil {
 		level = h.opts.Level.Level()
@@ -53,17 +151,62 @@ func (h *attrHandler) Enabled(_ context.Context, lvl slog.Level) bool {
 	return enabled
 }
 
-func (h *attrHandler) Handle(_ context.Context, rec slog.Record) (err error) {
+func (h *attrHandler) Handle(ctx context.Context, rec slog.Record) (err error) {
+	received := time.Now()
+	numCall := h.numCalls.Add(1)
+
+	// captured is the output of capturing the record, if it was built.
+	var captured *Envelope
+	if h.next == nil {
+		captured, err = h.capture(rec, received)
+	} else {
+		// Forward the record before capturing it, so that Next observes the
+		// record exactly as it was passed in.
+		var nextErr, captErr error
+		if h.next.Enabled(ctx, rec.Level) {
+			nextErr = h.next.Handle(ctx, rec)
+		}
+		if h.captureEnabled(rec.Level) {
+			captured, captErr = h.capture(rec, received)
+		}
+		err = errors.Join(captErr, nextErr)
+	}
+
+	if len(h.opts.Faults) > 0 {
+		if faultErr := h.injectFaults(ctx, numCall, rec, captured); faultErr != nil {
+			err = errors.Join(err, faultErr)
+		}
+	}
+	return
+}
+
+// capture builds the record and passes it along to the sink and the
+// CaptureRecord function. The received time is when Handle was called. The
+// output Envelope is nil if there's nowhere to pass the record, in which case
+// it's not built.
+func (h *attrHandler) capture(rec slog.Record, received time.Time) (built *Envelope, err error) {
 	capture := h.opts.CaptureRecord
-	if capture == nil {
+	if capture == nil && h.sink == nil {
 		return
 	}
 
-	out := h.buildRecordAttrs(rec)
+	rec.Time = h.recordTime(rec.Time)
+	out, buildErr := h.buildRecordAttrs(rec)
+	built = &out
 
 	h.mtx.Lock()
 	defer h.mtx.Unlock()
-	err = capture(out)
+	if h.sink != nil {
+		*h.seq++
+		out.Seq = *h.seq
+		out.GoroutineID = goroutineID()
+		out.Received = received
+		err = h.sink(out)
+	}
+	if capture != nil {
+		err = errors.Join(err, capture(out.Record))
+	}
+	err = errors.Join(err, buildErr)
 	return
 }
 
@@ -74,6 +217,9 @@ func (h *attrHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
 
 	out := *h
 	out.goas = &groupsOrAttrs{Attrs: attrs, Next: h.goas}
+	if h.next != nil {
+		out.next = h.next.WithAttrs(attrs)
+	}
 
 	return &out
 }
@@ -85,20 +231,29 @@ func (h *attrHandler) WithGroup(name string) slog.Handler {
 
 	out := *h
 	out.goas = &groupsOrAttrs{Group: name, Next: h.goas}
+	if h.next != nil {
+		out.next = h.next.WithGroup(name)
+	}
 
 	return &out
 }
 
-func (h *attrHandler) buildRecordAttrs(r slog.Record) slog.Record {
+// buildRecordAttrs makes a new record. The output only has the fields which
+// can be known from the input record. The time is used as is, so normalize it
+// beforehand.
+func (h *attrHandler) buildRecordAttrs(r slog.Record) (Envelope, error) {
 	out := slog.NewRecord(r.Time, r.Level, r.Message, r.PC)
+	var provenance Provenance
 
 	ab := attrBuilder{
-		replaceAttr: h.opts.ReplaceAttr,
-		attrsByPath: make(map[string]*attrWithPath, max(r.NumAttrs()-3, 0)),
-		results:     make([]slog.Attr, 0, r.NumAttrs()+3),
+		replaceAttr:     h.opts.ReplaceAttr,
+		duplicates:      h.opts.DuplicateKeys,
+		errorOnConflict: h.opts.ErrorOnConflict,
+		diagnostics:     h.opts.Diagnostics,
 	}
 
 	// Start with builtin attributes. These are already on the input record.
+	ab.origin = OriginBuiltin
 
 	// From slog.Handler docs:
 	// 	If r.Time is the zero time, ignore the time.
@@ -106,19 +261,30 @@ func (h *attrHandler) buildRecordAttrs(r slog.Record) slog.Record {
 		ab.buildAttr(nil, slog.Time(slog.TimeKey, r.Time))
 	}
 	ab.buildAttr(nil, slog.String(slog.LevelKey, r.Level.String()))
+	// Like the standard library handlers, the source is only added upon
+	// request and when the record has a program counter.
+	if h.opts.AddSource {
+		if src := r.Source(); src != nil {
+			ab.buildAttr(nil, slog.Any(slog.SourceKey, src))
+		}
+	}
 	ab.buildAttr(nil, slog.String(slog.MessageKey, r.Message))
 
 	// Work on the non builtin attributes.
 	// Start on data accumulated from .WithAttrs, .WithGroup.
+	ab.origin = OriginLoggerContext
 	groups := applyGroupsOrAttrs(h.goas, ab.buildAttr)
 
 	// Now work on attributes passed in from the logger's output method.
+	ab.origin = OriginCallSite
 	r.Attrs(func(a slog.Attr) bool {
 		ab.buildAttr(groups, a)
 		return true
 	})
 
-	ab.results = slices.Clip(ab.results)
-	out.AddAttrs(ab.results...)
-	return out
+	out.AddAttrs(ab.root.attrs()...)
+	if h.opts.TrackProvenance {
+		ab.root.addProvenance(&provenance, nil)
+	}
+	return Envelope{Record: out, Provenance: provenance, Conflicts: ab.conflicts}, ab.err()
 }
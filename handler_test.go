@@ -4,6 +4,7 @@ import (
 	"bytes"
 	"context"
 	"encoding/json"
+	"errors"
 	"io"
 	"log/slog"
 	"maps"
@@ -118,6 +119,60 @@ func TestAttrHandler(t *testing.T) {
 				}
 			},
 		},
+		{
+			name: "options.AddSource",
+			opts: &st.AttrHandlerOptions{HandlerOptions: slog.HandlerOptions{AddSource: true}},
+			action: func(t *testing.T, h slog.Handler) {
+				logInfo(h, "msg")
+
+				// A record without a PC should not have a source.
+				rec := slog.NewRecord(time.Now(), slog.LevelInfo, "msg", 0)
+				if err := h.Handle(context.Background(), rec); err != nil {
+					t.Error(err)
+				}
+			},
+			expect: func(t *testing.T, got []slog.Record) {
+				requireResultLen(t, got, 2)
+				attrs := st.GetRecordAttrs(got[0])
+				if attrs[2].Key != slog.SourceKey {
+					t.Errorf("expected source to follow builtins time, level; got key %q", attrs[2].Key)
+				}
+				check := st.HasSource("handler_test.go", "slogtesting_test.logInfo")
+				if err := check(attrs); err != nil {
+					t.Error(err)
+				}
+
+				check = st.MissingKey(slog.SourceKey)
+				if err := check(st.GetRecordAttrs(got[1])); err != nil {
+					t.Error(err)
+				}
+			},
+		},
+		{
+			name: "options.AddSource with ReplaceAttr",
+			opts: &st.AttrHandlerOptions{
+				HandlerOptions: slog.HandlerOptions{
+					AddSource: true,
+					ReplaceAttr: func(_ []string, a slog.Attr) slog.Attr {
+						if a.Key == slog.SourceKey {
+							a.Key = "caller"
+						}
+						return a
+					},
+				},
+			},
+			action: func(t *testing.T, h slog.Handler) {
+				slog.New(h).Info("msg")
+			},
+			expect: func(t *testing.T, got []slog.Record) {
+				requireResultLen(t, got, 1)
+				attrs := st.GetRecordAttrs(got[0])
+				check := st.HasKey("caller")
+				if err := check(attrs); err != nil {
+					t.Error(err)
+				}
+			},
+		},
 		{
 			name: "initialized with empty options",
 			opts: nil,
@@ -258,6 +313,143 @@ func TestAttrHandlerEnabled(t *testing.T) {
 	}
 }
 
+func TestAttrHandlerNext(t *testing.T) {
+	var buf bytes.Buffer
+	next := slog.NewJSONHandler(&buf, &slog.HandlerOptions{
+		Level: slog.LevelDebug,
+		ReplaceAttr: func(_ []string, a slog.Attr) slog.Attr {
+			if a.Key == slog.TimeKey {
+				a = slog.Attr{}
+			}
+			return a
+		},
+	})
+	rec := st.NewRecorder(&st.AttrHandlerOptions{
+		HandlerOptions: slog.HandlerOptions{Level: slog.LevelInfo},
+		Next:           next,
+	})
+
+	logger := slog.New(rec.Handler())
+	logger.With("a", "b").WithGroup("G").Info("msg", "c", "d")
+	logger.Debug("only forwarded")
+
+	expLines := []string{
+		`{"level":"INFO","msg":"msg","a":"b","G":{"c":"d"}}`,
+		`{"level":"DEBUG","msg":"only forwarded"}`,
+	}
+	gotLines := bytes.Split(bytes.TrimSpace(buf.Bytes()), []byte("\n"))
+	requireResultLen(t, gotLines, len(expLines))
+	for i, got := range gotLines {
+		if string(got) != expLines[i] {
+			t.Errorf("wrong forwarded output [%d]\ngot: %s\nexp: %s", i, got, expLines[i])
+		}
+	}
+
+	records := rec.Records()
+	requireResultLen(t, records, 1)
+	check := st.InGroup("G", st.HasAttr(slog.String("c", "d")))
+	if err := check(st.GetRecordAttrs(records[0])); err != nil {
+		t.Error(err)
+	}
+
+	t.Run("enabled", func(t *testing.T) {
+		h := rec.Handler()
+		if !h.Enabled(context.Background(), slog.LevelDebug) {
+			t.Error("expected handler to be enabled at a level allowed by Next")
+		}
+		h = st.NewAttrHandler(&st.AttrHandlerOptions{
+			HandlerOptions: slog.HandlerOptions{Level: slog.LevelWarn},
+			Next:           slog.NewJSONHandler(io.Discard, &slog.HandlerOptions{Level: slog.LevelError}),
+		})
+		if h.Enabled(context.Background(), slog.LevelInfo) {
+			t.Error("expected handler to be disabled at a level allowed by neither handler")
+		}
+	})
+
+	t.Run("errors", func(t *testing.T) {
+		nextErr := errors.New("next")
+		captErr := errors.New("capture")
+		h := st.NewAttrHandler(&st.AttrHandlerOptions{
+			CaptureRecord: func(slog.Record) error { return captErr },
+			Next:          errorHandler{err: nextErr},
+		})
+		err := h.Handle(context.Background(), slog.NewRecord(time.Now(), slog.LevelInfo, "msg", 0))
+		if !errors.Is(err, nextErr) {
+			t.Errorf("expected error to include %v; got %v", nextErr, err)
+		}
+		if !errors.Is(err, captErr) {
+			t.Errorf("expected error to include %v; got %v", captErr, err)
+		}
+	})
+}
+
+// errorHandler is a slog.Handler whose Handle method always fails.
+type errorHandler struct{ err error }
+
+func (h errorHandler) Enabled(context.Context, slog.Level) bool  { return true }
+func (h errorHandler) Handle(context.Context, slog.Record) error { return h.err }
+func (h errorHandler) WithAttrs([]slog.Attr) slog.Handler        { return h }
+func (h errorHandler) WithGroup(string) slog.Handler             { return h }
+
+func TestAttrHandlerDuplicateKeys(t *testing.T) {
+	var captured []slog.Record
+	h := st.NewAttrHandler(&st.AttrHandlerOptions{
+		CaptureRecord: func(r slog.Record) error { captured = append(captured, r); return nil },
+		DuplicateKeys: st.DuplicateError,
+	})
+
+	err := h.WithGroup("G").Handle(context.Background(), slog.NewRecord(time.Now(), slog.LevelInfo, "m", 0))
+	if err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+
+	rec := slog.NewRecord(time.Now(), slog.LevelInfo, "m", 0)
+	rec.Add("a", 1, "a", 2)
+	err = h.WithGroup("G").Handle(context.Background(), rec)
+	if !errors.Is(err, st.ErrDuplicateKey) {
+		t.Errorf("expected error (%v) to match %v", err, st.ErrDuplicateKey)
+	}
+	requireResultLen(t, captured, 2)
+}
+
+func TestAttrHandlerDiagnostics(t *testing.T) {
+	debugOpts := &st.AttrHandlerOptions{HandlerOptions: slog.HandlerOptions{Level: slog.LevelDebug}}
+	defaultRec := st.CaptureDefault(t, debugOpts)
+	diagnostics := st.NewRecorder(debugOpts)
+
+	rec := st.NewRecorder(&st.AttrHandlerOptions{Diagnostics: diagnostics.Handler()})
+	slog.New(rec.Handler()).With("G", 1).WithGroup("G").Info("msg", "a", 2)
+	slog.New(st.NewAttrHandler(nil)).With("G", 1).WithGroup("G").Info("msg", "a", 2)
+
+	if n := defaultRec.Len(); n != 0 {
+		t.Errorf("expected no records on the default logger; got %d", n)
+	}
+
+	records := diagnostics.Records()
+	// The builtins time, level and msg, then G, the group G, and a.
+	requireResultLen(t, records, 6)
+
+	tests := []st.Check{
+		st.HasAttr(slog.String("decision", "added")),
+		st.HasAttr(slog.String("decision", "added")),
+		st.HasAttr(slog.String("decision", "added")),
+		st.HasAttr(slog.String("decision", "added")),
+		st.HasAttr(slog.String("decision", "conflict")),
+		st.HasAttr(slog.String("decision", "conflict")),
+	}
+	for i, check := range tests {
+		if err := check(st.GetRecordAttrs(records[i])); err != nil {
+			t.Errorf("record[%d] %v", i, err)
+		}
+	}
+	if err := st.HasAttr(slog.String("path", "G.a"))(st.GetRecordAttrs(records[5])); err != nil {
+		t.Error(err)
+	}
+	if err := st.HasAttr(slog.String("conflicts_with", "G"))(st.GetRecordAttrs(records[5])); err != nil {
+		t.Error(err)
+	}
+}
+
 func TestAttrHandlerNoCapture(t *testing.T) {
 	// Sanity check that a panic does not occur when a Handler is initialized
 	// without a record capture callback.
@@ -274,6 +466,9 @@ func TestAttrHandlerNoCapture(t *testing.T) {
 	}
 }
 
+// logInfo is a named function to help test the source of a record.
+func logInfo(h slog.Handler, msg string, args ...any) { slog.New(h).Info(msg, args...) }
+
 func makeJSONRecordCapturer(w io.Writer) func(r slog.Record) error {
 	return func(r slog.Record) error {
 		attrs := st.GetRecordAttrs(r)
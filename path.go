@@ -0,0 +1,209 @@
+package slogtesting
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+	"strings"
+)
+
+// attrPath is a parsed path expression. See AtPath for the syntax.
+type attrPath struct {
+	expr     string
+	segments []pathSegment
+}
+
+type pathSegment struct {
+	key      string
+	wildcard bool // "*"
+	descent  bool // "**"
+}
+
+// parsePath parses a path expression. If it's invalid, then offset is the byte
+// offset in expr where the problem was found.
+func parsePath(expr string) (out attrPath, offset int, err error) {
+	if expr == "" {
+		return out, 0, errors.New("invalid path: it's empty")
+	}
+	out.expr = expr
+
+	var sb strings.Builder
+	escaped, hasEscape := false, false
+	start := 0
+
+	endSegment := func(pos int) error {
+		key := sb.String()
+		switch {
+		case key == "" && !hasEscape:
+			offset = start
+			return fmt.Errorf("invalid path %q: empty key at offset %d", expr, start)
+		case key == "*" && !hasEscape:
+			out.segments = append(out.segments, pathSegment{wildcard: true})
+		case key == "**" && !hasEscape:
+			out.segments = append(out.segments, pathSegment{descent: true})
+		default:
+			out.segments = append(out.segments, pathSegment{key: key})
+		}
+		sb.Reset()
+		hasEscape = false
+		start = pos + 1
+		return nil
+	}
+
+	for i, r := range expr {
+		switch {
+		case escaped:
+			sb.WriteRune(r)
+			escaped = false
+		case r == '\\':
+			escaped, hasEscape = true, true
+		case r == '.':
+			if err = endSegment(i); err != nil {
+				return
+			}
+		default:
+			sb.WriteRune(r)
+		}
+	}
+	if escaped {
+		return out, len(expr) - 1, fmt.Errorf("invalid path %q: escape character at the end", expr)
+	}
+	last := start
+	if err = endSegment(len(expr)); err != nil {
+		return
+	}
+	if out.segments[len(out.segments)-1].descent {
+		return out, last, fmt.Errorf("invalid path %q: %q must be followed by a key", expr, "**")
+	}
+	return
+}
+
+// match reports whether the keys of an attribute, including the keys of its
+// groups, are named by p.
+func (p attrPath) match(keys []string) bool { return matchSegments(p.segments, keys) }
+
+func matchSegments(segments []pathSegment, keys []string) bool {
+	if len(segments) < 1 {
+		return len(keys) < 1
+	}
+
+	seg := segments[0]
+	if seg.descent {
+		if matchSegments(segments[1:], keys) {
+			return true
+		}
+		return len(keys) > 0 && matchSegments(segments, keys[1:])
+	}
+	if len(keys) < 1 || (!seg.wildcard && seg.key != keys[0]) {
+		return false
+	}
+	return matchSegments(segments[1:], keys[1:])
+}
+
+// pathMatch is an attribute found with an attrPath.
+type pathMatch struct {
+	groups []string // Keys of the groups of attr, outermost first.
+	attr   slog.Attr
+}
+
+// find collects the attributes named by p, at any depth in attrs, in the
+// order they appear.
+func (p attrPath) find(attrs []slog.Attr) (out []pathMatch) {
+	walkAttrs(attrs, func(groups []string, a slog.Attr) {
+		if p.match(appendPath(groups, a.Key)) {
+			out = append(out, pathMatch{groups: groups, attr: a})
+		}
+	})
+	return
+}
+
+// hasMatchAt makes a Check for at least 1 attribute named by p which passes
+// match. Unlike AtPath, the other attributes named by p do not matter.
+func hasMatchAt(p attrPath, match matcher) Check {
+	return func(attrs []slog.Attr) error {
+		for _, m := range p.find(attrs) {
+			if match(m.attr) {
+				return nil
+			}
+		}
+		return fmt.Errorf("did not find a matching attr at path %s", p.expr)
+	}
+}
+
+// AtPath makes a Check for attributes named by a path expression. For each
+// attribute that is a group, the input Checks are run on the attributes in the
+// group, as with [InGroup]. For any other attribute, they're run on a slice of
+// just that attribute. Each attribute must pass each Check, and at least 1
+// attribute is required. Errors have the group path of the attribute.
+//
+// A path expression is the keys of the groups of an attribute followed by its
+// own key, separated by ".", such as "G.H.e". Within a key, a "\" escapes the
+// next character, as in "a\.b" for the key "a.b". A segment that is only "*"
+// matches any 1 key, as in "G.*.e", and a segment that is only "**" matches any
+// number of groups, including none, as in "**.e". An invalid path makes a
+// Check which always fails.
+func AtPath(path string, c Check, moreChecks ...Check) Check {
+	checks := append([]Check{c}, moreChecks...)
+	p, _, err := parsePath(path)
+	return func(attrs []slog.Attr) error {
+		if err != nil {
+			return err
+		}
+
+		matches := p.find(attrs)
+		if len(matches) < 1 {
+			return errors.Join(fmt.Errorf("did not find attr at path %s", path))
+		}
+
+		var errs []error
+		for _, m := range matches {
+			groupPath, members := m.groups, []slog.Attr{m.attr}
+			if m.attr.Value.Kind() == slog.KindGroup {
+				groupPath, members = appendPath(m.groups, m.attr.Key), m.attr.Value.Group()
+			}
+			for _, check := range checks {
+				if err := check(members); err != nil {
+					errs = append(errs, wrapGroupPath(err, groupPath))
+				}
+			}
+		}
+		return errors.Join(errs...)
+	}
+}
+
+// HasAttrAt makes a Check for attributes named by a path expression, whose
+// values equal want. Each attribute must have the value, and at least 1 is
+// required. See [AtPath] for the syntax of a path expression.
+func HasAttrAt(path string, want slog.Value) Check {
+	p, _, err := parsePath(path)
+	return func(attrs []slog.Attr) error {
+		if err != nil {
+			return err
+		}
+
+		matches := p.find(attrs)
+		if len(matches) < 1 {
+			return errors.Join(fmt.Errorf("did not find attr at path %s", path))
+		}
+
+		var errs []error
+		for _, m := range matches {
+			if got := m.attr.Value; !got.Equal(want) {
+				err := fmt.Errorf(
+					"values not equal for key %q\ngot_val_kind %q, want_val_kind %q\ngot_val %v want_val %v",
+					m.attr.Key, got.Kind().String(), want.Kind().String(), got, want,
+				)
+				errs = append(errs, wrapGroupPath(err, m.groups))
+			}
+		}
+		return errors.Join(errs...)
+	}
+}
+
+// wrapGroupPath adds the group path to err, as nested calls to InGroup would.
+func wrapGroupPath(err error, groups []string) error {
+	for i := len(groups) - 1; i >= 0; i-- {
+		err = makeErrorWithGroupPath(err, groups[i])
+	}
+	return err
+}
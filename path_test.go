@@ -0,0 +1,93 @@
+package slogtesting_test
+
+import (
+	"log/slog"
+	"slices"
+	"strings"
+	"testing"
+
+	st "github.com/rafaelespinoza/slogtesting"
+)
+
+func TestAtPath(t *testing.T) {
+	attrs := []slog.Attr{
+		slog.String("a", "b"),
+		slog.String("x.y", "dotted"),
+		slog.String("*", "star"),
+		slog.Group("G",
+			slog.String("c", "d"),
+			slog.Group("H", slog.String("e", "f")),
+			slog.Group("I", slog.String("e", "f"), slog.Int("n", 1)),
+		),
+		slog.String("e", "f"),
+	}
+
+	tests := []struct {
+		name          string
+		check         st.Check
+		expErr        string
+		expGroupPaths [][]string // 1 slice per expected error with a group path
+	}{
+		{name: "top level", check: st.HasAttrAt("a", slog.StringValue("b"))},
+		{name: "nested", check: st.HasAttrAt("G.H.e", slog.StringValue("f"))},
+		{name: "escaped dot", check: st.HasAttrAt(`x\.y`, slog.StringValue("dotted"))},
+		{name: "escaped star", check: st.HasAttrAt(`\*`, slog.StringValue("star"))},
+		{name: "wildcard", check: st.HasAttrAt("G.*.e", slog.StringValue("f"))},
+		{name: "recursive descent", check: st.HasAttrAt("**.e", slog.StringValue("f"))},
+		{name: "group", check: st.AtPath("G.I", st.HasAttr(slog.Int("n", 1)), st.HasKey("e"))},
+		{name: "leaf", check: st.AtPath("G.c", st.HasAttr(slog.String("c", "d")))},
+		{
+			name:   "group fail",
+			check:  st.AtPath("G.*", st.HasKey("n")),
+			expErr: "did not find expected key n",
+			// G.c is a match too, so the check runs on it alone.
+			expGroupPaths: [][]string{{"G"}, {"G", "H"}},
+		},
+		{
+			name:          "value fail",
+			check:         st.HasAttrAt("G.**.n", slog.IntValue(2)),
+			expErr:        `values not equal for key "n"`,
+			expGroupPaths: [][]string{{"G", "I"}},
+		},
+		{name: "not found", check: st.HasAttrAt("G.x", slog.IntValue(1)), expErr: "did not find attr at path G.x"},
+		{name: "empty path", check: st.HasAttrAt("", slog.IntValue(1)), expErr: "invalid path"},
+		{name: "empty key", check: st.HasAttrAt("G..e", slog.IntValue(1)), expErr: "empty key at offset 2"},
+		{name: "trailing escape", check: st.HasAttrAt(`G\`, slog.IntValue(1)), expErr: "escape character at the end"},
+		{name: "trailing descent", check: st.HasAttrAt("G.**", slog.IntValue(1)), expErr: `"**" must be followed by a key`},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			err := test.check(attrs)
+			if test.expErr == "" {
+				if err != nil {
+					t.Fatalf("unexpected error %v", err)
+				}
+				return
+			}
+
+			if err == nil {
+				t.Fatal("expected an error but got nil")
+			}
+			t.Log(err)
+			if !strings.Contains(err.Error(), test.expErr) {
+				t.Errorf("expected error message %q to contain %q", err.Error(), test.expErr)
+			}
+			if len(test.expGroupPaths) < 1 {
+				return
+			}
+
+			unwrappedErrs := err.(interface{ Unwrap() []error }).Unwrap()
+			requireResultLen(t, unwrappedErrs, len(test.expGroupPaths))
+			for i, uerr := range unwrappedErrs {
+				errWithPath, ok := uerr.(interface{ GroupPath() []string })
+				if !ok {
+					t.Fatal("expected error to implement expected interface with GroupPath method")
+				}
+				if got := errWithPath.GroupPath(); !slices.Equal(got, test.expGroupPaths[i]) {
+					t.Errorf("group path wrong; got %q, expected %q", got, test.expGroupPaths[i])
+				}
+			}
+		})
+	}
+}
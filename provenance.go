@@ -0,0 +1,107 @@
+package slogtesting
+
+import (
+	"fmt"
+	"slices"
+)
+
+// An Origin is where an attribute of a captured record came from.
+type Origin int
+
+const (
+	// OriginUnknown means that the origin could not be determined.
+	OriginUnknown Origin = iota
+	// OriginBuiltin is for the time, level, message and source.
+	OriginBuiltin
+	// OriginLoggerContext is for data added with WithAttrs, such as via
+	// [slog.Logger.With].
+	OriginLoggerContext
+	// OriginCallSite is for data passed to the logger's output method, such as
+	// the args to [slog.Logger.Info].
+	OriginCallSite
+	// OriginReplaceAttr is for data that was changed by the ReplaceAttr field
+	// of [slog.HandlerOptions].
+	OriginReplaceAttr
+)
+
+func (o Origin) String() string {
+	switch o {
+	case OriginBuiltin:
+		return "builtin"
+	case OriginLoggerContext:
+		return "logger context"
+	case OriginCallSite:
+		return "call site"
+	case OriginReplaceAttr:
+		return "ReplaceAttr"
+	default:
+		return "unknown"
+	}
+}
+
+// A ProvenanceEntry is the Origin of the attribute at a path. The path is the
+// names of the groups containing the attribute, followed by its key.
+type ProvenanceEntry struct {
+	Path   []string
+	Origin Origin
+}
+
+// Provenance is an index of where each attribute of a captured record came
+// from. It only has entries for non-group attributes. It's built when the
+// TrackProvenance field of [AttrHandlerOptions] is true, and it's available via
+// [Recorder.Envelopes] or [Recorder.Provenances].
+type Provenance struct {
+	entries []ProvenanceEntry
+}
+
+// Entries lists the attributes in the order they appear in the record.
+func (p Provenance) Entries() []ProvenanceEntry { return slices.Clone(p.entries) }
+
+// Origin looks up the origin of the attribute at path. If path is for a group,
+// then the output is the origin shared by all attributes in the group. The
+// output is [OriginUnknown] if the path is not found, or if the attributes in
+// the group have different origins.
+func (p Provenance) Origin(path ...string) Origin {
+	out := OriginUnknown
+	for _, entry := range p.entries {
+		if len(entry.Path) < len(path) || !slices.Equal(entry.Path[:len(path)], path) {
+			continue
+		}
+		if out != OriginUnknown && out != entry.Origin {
+			return OriginUnknown
+		}
+		out = entry.Origin
+	}
+	return out
+}
+
+func (p *Provenance) add(groups []string, key string, origin Origin) {
+	path := append(slices.Clone(groups), key)
+	p.entries = append(p.entries, ProvenanceEntry{Path: path, Origin: origin})
+}
+
+// A ProvenanceCheck is a test on the [Provenance] of a captured record.
+type ProvenanceCheck func(Provenance) error
+
+// HasOrigin makes a ProvenanceCheck for the origin of the attribute at path.
+// See [Provenance.Origin] for how paths to groups are treated.
+func HasOrigin(want Origin, path ...string) ProvenanceCheck {
+	return func(p Provenance) (err error) {
+		if got := p.Origin(path...); got != want {
+			err = fmt.Errorf("wrong origin for attr at path %q; got %s, expected %s", path, got, want)
+		}
+		return
+	}
+}
+
+// FromLoggerContext makes a ProvenanceCheck that the attribute at path came
+// from the logger's context, rather than from an ad-hoc call site.
+func FromLoggerContext(path ...string) ProvenanceCheck {
+	return HasOrigin(OriginLoggerContext, path...)
+}
+
+// FromCallSite makes a ProvenanceCheck that the attribute at path came from the
+// arguments to the logger's output method.
+func FromCallSite(path ...string) ProvenanceCheck {
+	return HasOrigin(OriginCallSite, path...)
+}
@@ -0,0 +1,96 @@
+package slogtesting_test
+
+import (
+	"log/slog"
+	"testing"
+
+	st "github.com/rafaelespinoza/slogtesting"
+)
+
+func TestProvenance(t *testing.T) {
+	rec := st.NewRecorder(&st.AttrHandlerOptions{
+		HandlerOptions: slog.HandlerOptions{
+			ReplaceAttr: func(_ []string, a slog.Attr) slog.Attr {
+				if a.Key == "secret" {
+					a.Value = slog.StringValue("REDACTED")
+				}
+				return a
+			},
+		},
+		TrackProvenance: true,
+	})
+
+	slog.New(rec.Handler()).
+		With("request_id", "abc").
+		WithGroup("G").
+		With("c", "d").
+		Info("msg", "e", "f", "secret", "hunter2", slog.Group("H", "i", 1))
+
+	provenances := rec.Provenances()
+	requireResultLen(t, provenances, 1)
+	prov := provenances[0]
+
+	tests := []struct {
+		path []string
+		exp  st.Origin
+	}{
+		{path: []string{slog.TimeKey}, exp: st.OriginBuiltin},
+		{path: []string{slog.LevelKey}, exp: st.OriginBuiltin},
+		{path: []string{slog.MessageKey}, exp: st.OriginBuiltin},
+		{path: []string{"request_id"}, exp: st.OriginLoggerContext},
+		{path: []string{"G", "c"}, exp: st.OriginLoggerContext},
+		{path: []string{"G", "e"}, exp: st.OriginCallSite},
+		{path: []string{"G", "secret"}, exp: st.OriginReplaceAttr},
+		{path: []string{"G", "H", "i"}, exp: st.OriginCallSite},
+		{path: []string{"G", "H"}, exp: st.OriginCallSite},
+		{path: []string{"G"}, exp: st.OriginUnknown}, // mixed origins
+		{path: []string{"nope"}, exp: st.OriginUnknown},
+	}
+	for _, test := range tests {
+		if got := prov.Origin(test.path...); got != test.exp {
+			t.Errorf("wrong origin for path %q; got %s, expected %s", test.path, got, test.exp)
+		}
+	}
+
+	if got := len(prov.Entries()); got != 8 {
+		t.Errorf("wrong number of entries; got %d, expected %d", got, 8)
+	}
+
+	t.Run("checks", func(t *testing.T) {
+		checks := []st.ProvenanceCheck{
+			st.FromLoggerContext("request_id"),
+			st.FromLoggerContext("G", "c"),
+			st.FromCallSite("G", "e"),
+			st.HasOrigin(st.OriginReplaceAttr, "G", "secret"),
+		}
+		for i, check := range checks {
+			if err := check(prov); err != nil {
+				t.Errorf("check[%d] %v", i, err)
+			}
+		}
+
+		failures := []st.ProvenanceCheck{
+			st.FromLoggerContext("G", "e"),
+			st.FromCallSite("request_id"),
+			st.FromLoggerContext("nope"),
+		}
+		for i, check := range failures {
+			err := check(prov)
+			if err == nil {
+				t.Errorf("check[%d] expected an error but got nil", i)
+			}
+			t.Log(err)
+		}
+	})
+
+	t.Run("disabled", func(t *testing.T) {
+		rec := st.NewRecorder(nil)
+		slog.New(rec.Handler()).With("a", "b").Info("msg")
+
+		provenances := rec.Provenances()
+		requireResultLen(t, provenances, 1)
+		if got := provenances[0].Entries(); len(got) != 0 {
+			t.Errorf("expected empty provenance; got %v", got)
+		}
+	})
+}
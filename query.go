@@ -0,0 +1,519 @@
+package slogtesting
+
+import (
+	"cmp"
+	"fmt"
+	"log/slog"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+	"unicode/utf8"
+)
+
+// A Query selects records, and optionally attributes within them, with a
+// compact expression. It's for exploratory debugging and table-driven tests.
+// Create one with [ParseQuery].
+type Query struct {
+	text    string
+	pred    queryPredicate // A nil value matches each record.
+	selects []attrPath
+}
+
+// A queryPredicate tests a record. Its attributes, as output by
+// GetRecordAttrs, are passed along so that they're collected once per record.
+type queryPredicate func(r slog.Record, attrs []slog.Attr) bool
+
+// checkPredicate makes a queryPredicate out of a Check on the attributes.
+func checkPredicate(c Check) queryPredicate {
+	return func(_ slog.Record, attrs []slog.Attr) bool { return c(attrs) == nil }
+}
+
+// A QueryMatch is a record which matched a [Query].
+type QueryMatch struct {
+	// Index is the position of the record in the input to [Query.Run].
+	Index  int
+	Record slog.Record
+	// Selected has the attributes named in the select clause of the query,
+	// in the order of the clause. The key of each one is its full path,
+	// separated by ".", such as "G.H.e".
+	Selected []slog.Attr
+}
+
+// A QueryError describes why a query could not be parsed.
+type QueryError struct {
+	Query string
+	// Pos is the byte offset in Query where the problem was found.
+	Pos int
+	Msg string
+}
+
+// Error describes the problem, followed by the query and a line with a caret
+// under the position of the problem, as in:
+//
+//	slogtesting: invalid query at offset 7: expected a value after >=, found end of query
+//		level>=
+//		       ^
+func (e *QueryError) Error() string {
+	pos := min(max(e.Pos, 0), len(e.Query))
+	// Whitespace would misalign the caret, so show it all as spaces.
+	query := strings.Map(func(r rune) rune {
+		if unicode.IsSpace(r) {
+			return ' '
+		}
+		return r
+	}, e.Query)
+	caret := strings.Repeat(" ", utf8.RuneCountInString(e.Query[:pos])) + "^"
+	return fmt.Sprintf("%sinvalid query at offset %d: %s\n\t%s\n\t%s", logPrefix, e.Pos, e.Msg, query, caret)
+}
+
+// ParseQuery parses a query, such as:
+//
+//	level>=WARN and msg~"retry" | select G.H.e
+//
+// A query is an expression which selects records, optionally followed by a "|"
+// and a select clause, which names the attributes to output. An empty
+// expression selects each record. An expression is made of:
+//   - Comparisons, such as a=1, of a field or attribute path on the left, an
+//     operator, and a value on the right.
+//   - The fields level and msg, for the level and the message of a record. A
+//     level is compared by severity, as in level>=WARN or level<INFO+2.
+//   - Attribute paths, with the syntax of [AtPath]. A path on its own, such as
+//     G.H.e, tests for the presence of an attribute. A comparison with a path
+//     is true if at least 1 attribute at the path satisfies it. A value is
+//     compared according to the kind of the attribute: as a number, bool,
+//     duration, RFC 3339 time, or otherwise as a string.
+//   - The operators =, !=, <, <=, >, >= and ~. The ~ operator matches a
+//     regular expression against the value as a string.
+//   - Values, which are either a word, such as WARN or 1.5, or a quoted string,
+//     such as "retry later", with the escapes of a Go string literal.
+//   - The keywords and, or, not, in order from the lowest to the highest
+//     precedence, and parentheses for grouping.
+//
+// The select clause is the keyword select followed by paths separated by ",".
+// To use a keyword or a field name as a key, escape it, as in \level or \not.
+func ParseQuery(q string) (*Query, error) {
+	tokens, err := lexQuery(q)
+	if err != nil {
+		return nil, err
+	}
+
+	p := queryParser{query: q, tokens: tokens}
+	out := Query{text: q}
+
+	if kind := p.peek().kind; kind != tokenPipe && kind != tokenEOF {
+		if out.pred, err = p.parseOr(); err != nil {
+			return nil, err
+		}
+	}
+
+	if p.peek().kind == tokenPipe {
+		p.next()
+		if tok := p.next(); tok.kind != tokenWord || tok.text != "select" {
+			return nil, p.errorf(tok.pos, "expected select, found %s", tok)
+		}
+		for {
+			tok := p.next()
+			if tok.kind != tokenWord {
+				return nil, p.errorf(tok.pos, "expected a path, found %s", tok)
+			}
+			path, err := p.parsePath(tok)
+			if err != nil {
+				return nil, err
+			}
+			out.selects = append(out.selects, path)
+
+			if p.peek().kind != tokenComma {
+				break
+			}
+			p.next()
+		}
+	}
+
+	if tok := p.peek(); tok.kind != tokenEOF {
+		return nil, p.errorf(tok.pos, "unexpected %s", tok)
+	}
+	return &out, nil
+}
+
+// String outputs the text of the query.
+func (q *Query) String() string { return q.text }
+
+// Match reports whether r is selected by the query. The select clause does not
+// matter here.
+func (q *Query) Match(r slog.Record) bool {
+	return q.pred == nil || q.pred(r, GetRecordAttrs(r))
+}
+
+// Run outputs each record selected by the query, along with the attributes
+// named in its select clause.
+func (q *Query) Run(records []slog.Record) (out []QueryMatch) {
+	for i, r := range records {
+		attrs := GetRecordAttrs(r)
+		if q.pred != nil && !q.pred(r, attrs) {
+			continue
+		}
+
+		match := QueryMatch{Index: i, Record: r}
+		for _, path := range q.selects {
+			for _, m := range path.find(attrs) {
+				key := strings.Join(appendPath(m.groups, m.attr.Key), ".")
+				match.Selected = append(match.Selected, slog.Attr{Key: key, Value: m.attr.Value})
+			}
+		}
+		out = append(out, match)
+	}
+	return
+}
+
+type queryTokenKind int
+
+const (
+	tokenEOF queryTokenKind = iota
+	tokenWord
+	tokenString
+	tokenOperator
+	tokenLeftParen
+	tokenRightParen
+	tokenComma
+	tokenPipe
+)
+
+type queryToken struct {
+	kind queryTokenKind
+	// text is the raw text of a word, with any escapes, or the contents of a
+	// string, without the quotes.
+	text string
+	pos  int
+}
+
+func (t queryToken) String() string {
+	switch t.kind {
+	case tokenEOF:
+		return "end of query"
+	case tokenString:
+		return strconv.Quote(t.text)
+	default:
+		return "'" + t.text + "'"
+	}
+}
+
+func lexQuery(q string) (out []queryToken, err error) {
+	for i := 0; i < len(q); {
+		c := q[i]
+		switch {
+		case c == ' ' || c == '\t' || c == '\r' || c == '\n':
+			i++
+		case c == '(':
+			out = append(out, queryToken{kind: tokenLeftParen, text: "(", pos: i})
+			i++
+		case c == ')':
+			out = append(out, queryToken{kind: tokenRightParen, text: ")", pos: i})
+			i++
+		case c == ',':
+			out = append(out, queryToken{kind: tokenComma, text: ",", pos: i})
+			i++
+		case c == '|':
+			out = append(out, queryToken{kind: tokenPipe, text: "|", pos: i})
+			i++
+		case c == '=' || c == '~':
+			out = append(out, queryToken{kind: tokenOperator, text: q[i : i+1], pos: i})
+			i++
+		case c == '!' || c == '<' || c == '>':
+			n := 1
+			if i+1 < len(q) && q[i+1] == '=' {
+				n = 2
+			} else if c == '!' {
+				return nil, &QueryError{Query: q, Pos: i, Msg: "expected '=' after '!'"}
+			}
+			out = append(out, queryToken{kind: tokenOperator, text: q[i : i+n], pos: i})
+			i += n
+		case c == '"':
+			j := i + 1
+			for j < len(q) && q[j] != '"' {
+				if q[j] == '\\' {
+					j++
+				}
+				j++
+			}
+			if j >= len(q) {
+				return nil, &QueryError{Query: q, Pos: i, Msg: "unterminated string"}
+			}
+			text, err := strconv.Unquote(q[i : j+1])
+			if err != nil {
+				return nil, &QueryError{Query: q, Pos: i, Msg: "invalid string: " + err.Error()}
+			}
+			out = append(out, queryToken{kind: tokenString, text: text, pos: i})
+			i = j + 1
+		default:
+			j := i
+			for j < len(q) && !strings.ContainsRune(" \t\r\n(),|=~!<>\"", rune(q[j])) {
+				if q[j] == '\\' {
+					j++
+				}
+				j++
+			}
+			j = min(j, len(q))
+			out = append(out, queryToken{kind: tokenWord, text: q[i:j], pos: i})
+			i = j
+		}
+	}
+
+	out = append(out, queryToken{kind: tokenEOF, pos: len(q)})
+	return
+}
+
+type queryParser struct {
+	query  string
+	tokens []queryToken
+	i      int
+}
+
+func (p *queryParser) peek() queryToken { return p.tokens[p.i] }
+
+func (p *queryParser) next() queryToken {
+	tok := p.tokens[p.i]
+	if tok.kind != tokenEOF {
+		p.i++
+	}
+	return tok
+}
+
+func (p *queryParser) errorf(pos int, format string, args ...any) error {
+	return &QueryError{Query: p.query, Pos: pos, Msg: fmt.Sprintf(format, args...)}
+}
+
+// parsePath parses a word token as a path. A problem is reported at its
+// position within the token.
+func (p *queryParser) parsePath(tok queryToken) (attrPath, error) {
+	path, offset, err := parsePath(tok.text)
+	if err != nil {
+		return path, p.errorf(tok.pos+offset, "%v", err)
+	}
+	return path, nil
+}
+
+func (p *queryParser) isKeyword(keyword string) bool {
+	tok := p.peek()
+	return tok.kind == tokenWord && tok.text == keyword
+}
+
+func (p *queryParser) parseOr() (queryPredicate, error) {
+	left, err := p.parseAnd()
+	if err != nil {
+		return nil, err
+	}
+	for p.isKeyword("or") {
+		p.next()
+		right, err := p.parseAnd()
+		if err != nil {
+			return nil, err
+		}
+		left = func(lhs, rhs queryPredicate) queryPredicate {
+			return func(r slog.Record, attrs []slog.Attr) bool { return lhs(r, attrs) || rhs(r, attrs) }
+		}(left, right)
+	}
+	return left, nil
+}
+
+func (p *queryParser) parseAnd() (queryPredicate, error) {
+	left, err := p.parseNot()
+	if err != nil {
+		return nil, err
+	}
+	for p.isKeyword("and") {
+		p.next()
+		right, err := p.parseNot()
+		if err != nil {
+			return nil, err
+		}
+		left = func(lhs, rhs queryPredicate) queryPredicate {
+			return func(r slog.Record, attrs []slog.Attr) bool { return lhs(r, attrs) && rhs(r, attrs) }
+		}(left, right)
+	}
+	return left, nil
+}
+
+func (p *queryParser) parseNot() (queryPredicate, error) {
+	if !p.isKeyword("not") {
+		return p.parsePrimary()
+	}
+
+	p.next()
+	inner, err := p.parseNot()
+	if err != nil {
+		return nil, err
+	}
+	return func(r slog.Record, attrs []slog.Attr) bool { return !inner(r, attrs) }, nil
+}
+
+func (p *queryParser) parsePrimary() (queryPredicate, error) {
+	tok := p.next()
+	switch tok.kind {
+	case tokenLeftParen:
+		inner, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+		if closing := p.next(); closing.kind != tokenRightParen {
+			return nil, p.errorf(closing.pos, "expected ')', found %s", closing)
+		}
+		return inner, nil
+	case tokenWord:
+		switch tok.text {
+		case "and", "or", "not", "select":
+			return nil, p.errorf(tok.pos, "unexpected keyword %s", tok)
+		}
+	default:
+		return nil, p.errorf(tok.pos, "expected a comparison, a path or '(', found %s", tok)
+	}
+
+	if p.peek().kind != tokenOperator {
+		if tok.text == "level" || tok.text == "msg" {
+			return nil, p.errorf(p.peek().pos, "expected an operator after %s, found %s", tok.text, p.peek())
+		}
+		path, err := p.parsePath(tok)
+		if err != nil {
+			return nil, err
+		}
+		return checkPredicate(hasMatchAt(path, func(slog.Attr) bool { return true })), nil
+	}
+
+	op := p.next().text
+	lit := p.next()
+	if lit.kind != tokenWord && lit.kind != tokenString {
+		return nil, p.errorf(lit.pos, "expected a value after %s, found %s", op, lit)
+	}
+	value := lit.text
+	if lit.kind == tokenWord {
+		value = unescapeWord(value)
+	}
+
+	var re *regexp.Regexp
+	if op == "~" {
+		var err error
+		if re, err = regexp.Compile(value); err != nil {
+			return nil, p.errorf(lit.pos, "invalid regular expression: %v", err)
+		}
+	}
+
+	switch tok.text {
+	case "level":
+		if re != nil {
+			return func(r slog.Record, _ []slog.Attr) bool { return re.MatchString(r.Level.String()) }, nil
+		}
+		var want slog.Level
+		if n, err := strconv.Atoi(value); err == nil {
+			want = slog.Level(n)
+		} else if err = want.UnmarshalText([]byte(value)); err != nil {
+			return nil, p.errorf(lit.pos, "invalid level %q", value)
+		}
+		return func(r slog.Record, _ []slog.Attr) bool { return compareResult(cmp.Compare(r.Level, want), op) }, nil
+	case "msg":
+		if re != nil {
+			return func(r slog.Record, _ []slog.Attr) bool { return re.MatchString(r.Message) }, nil
+		}
+		return func(r slog.Record, _ []slog.Attr) bool { return compareResult(strings.Compare(r.Message, value), op) }, nil
+	}
+
+	path, err := p.parsePath(tok)
+	if err != nil {
+		return nil, err
+	}
+	return checkPredicate(hasMatchAt(path, func(a slog.Attr) bool {
+		if re != nil {
+			return re.MatchString(a.Value.String())
+		}
+		c, ok := compareQueryValue(a.Value, value)
+		return (ok && compareResult(c, op)) || (!ok && op == "!=")
+	})), nil
+}
+
+// unescapeWord removes the escape characters from a word of a query.
+func unescapeWord(s string) string {
+	if !strings.Contains(s, `\`) {
+		return s
+	}
+
+	var sb strings.Builder
+	escaped := false
+	for _, r := range s {
+		if !escaped && r == '\\' {
+			escaped = true
+			continue
+		}
+		escaped = false
+		sb.WriteRune(r)
+	}
+	return sb.String()
+}
+
+// compareResult applies an operator to the result of a comparison function,
+// such as [cmp.Compare].
+func compareResult(c int, op string) bool {
+	switch op {
+	case "=":
+		return c == 0
+	case "!=":
+		return c != 0
+	case "<":
+		return c < 0
+	case "<=":
+		return c <= 0
+	case ">":
+		return c > 0
+	case ">=":
+		return c >= 0
+	}
+	return false
+}
+
+// compareQueryValue compares v to a value from a query, according to the kind
+// of v. The output ok is false if the value from the query cannot be converted
+// to the kind of v.
+func compareQueryValue(v slog.Value, s string) (c int, ok bool) {
+	switch v.Kind() {
+	case slog.KindInt64:
+		if n, err := strconv.ParseInt(s, 10, 64); err == nil {
+			return cmp.Compare(v.Int64(), n), true
+		}
+		if f, err := strconv.ParseFloat(s, 64); err == nil {
+			return cmp.Compare(float64(v.Int64()), f), true
+		}
+	case slog.KindUint64:
+		if n, err := strconv.ParseUint(s, 10, 64); err == nil {
+			return cmp.Compare(v.Uint64(), n), true
+		}
+		if f, err := strconv.ParseFloat(s, 64); err == nil {
+			return cmp.Compare(float64(v.Uint64()), f), true
+		}
+	case slog.KindFloat64:
+		if f, err := strconv.ParseFloat(s, 64); err == nil {
+			return cmp.Compare(v.Float64(), f), true
+		}
+	case slog.KindBool:
+		if b, err := strconv.ParseBool(s); err == nil {
+			return cmp.Compare(boolToInt(v.Bool()), boolToInt(b)), true
+		}
+	case slog.KindDuration:
+		if d, err := time.ParseDuration(s); err == nil {
+			return cmp.Compare(v.Duration(), d), true
+		}
+	case slog.KindTime:
+		if t, err := time.Parse(time.RFC3339Nano, s); err == nil {
+			return v.Time().Compare(t), true
+		}
+	case slog.KindGroup:
+	default:
+		return strings.Compare(v.String(), s), true
+	}
+	return
+}
+
+func boolToInt(b bool) int {
+	if b {
+		return 1
+	}
+	return 0
+}
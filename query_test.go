@@ -0,0 +1,142 @@
+package slogtesting_test
+
+import (
+	"errors"
+	"log/slog"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	st "github.com/rafaelespinoza/slogtesting"
+)
+
+func TestQuery(t *testing.T) {
+	rec := st.NewRecorder(&st.AttrHandlerOptions{HandlerOptions: slog.HandlerOptions{Level: slog.LevelDebug}})
+	logger := slog.New(rec.Handler())
+	logger.Debug("starting", "attempt", 0)
+	logger.Info("request", slog.Group("G", slog.Group("H", "e", "alpha"), "ok", true), "elapsed", 5*time.Millisecond)
+	logger.Warn("retry soon", "attempt", 1, "level", "custom")
+	logger.Error("retry failed", "attempt", 2, slog.Group("G", slog.Group("H", "e", "bravo")), "rate", 0.5)
+	records := rec.Records()
+
+	tests := []struct {
+		query       string
+		expIndexes  []int
+		expSelected [][]slog.Attr
+	}{
+		{query: "", expIndexes: []int{0, 1, 2, 3}},
+		{query: "level>=WARN", expIndexes: []int{2, 3}},
+		{query: "level<INFO+2 and level!=DEBUG", expIndexes: []int{1}},
+		{query: "level=-4", expIndexes: []int{0}},
+		{query: "level~\"^(WARN|ERROR)$\"", expIndexes: []int{2, 3}},
+		{query: `msg~"retry"`, expIndexes: []int{2, 3}},
+		{query: `msg="retry soon"`, expIndexes: []int{2}},
+		{query: "attempt>=1", expIndexes: []int{2, 3}},
+		{query: "attempt>0.5", expIndexes: []int{2, 3}},
+		{query: "rate<1", expIndexes: []int{3}},
+		{query: "elapsed<=10ms", expIndexes: []int{1}},
+		{query: "G.ok=true", expIndexes: []int{1}},
+		{query: "G.H.e", expIndexes: []int{1, 3}},
+		{query: "**.e=bravo", expIndexes: []int{3}},
+		{query: `\level=custom`, expIndexes: []int{2}},
+		{query: "not attempt", expIndexes: []int{1}},
+		{query: "not (attempt=0 or attempt=2)", expIndexes: []int{1, 2}},
+		{query: "attempt=0 or attempt=1 and msg~soon", expIndexes: []int{0, 2}},
+		{
+			query:      `level>=INFO and msg~"re" | select G.H.e, attempt`,
+			expIndexes: []int{1, 2, 3},
+			expSelected: [][]slog.Attr{
+				{slog.String("G.H.e", "alpha")},
+				{slog.Int("attempt", 1)},
+				{slog.String("G.H.e", "bravo"), slog.Int("attempt", 2)},
+			},
+		},
+		{
+			query:       "| select G.*",
+			expIndexes:  []int{0, 1, 2, 3},
+			expSelected: [][]slog.Attr{nil, {slog.Group("G.H", slog.String("e", "alpha")), slog.Bool("G.ok", true)}, nil, {slog.Group("G.H", slog.String("e", "bravo"))}},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.query, func(t *testing.T) {
+			q, err := st.ParseQuery(test.query)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			matches := q.Run(records)
+			gotIndexes := make([]int, len(matches))
+			for i, m := range matches {
+				gotIndexes[i] = m.Index
+				if !q.Match(m.Record) {
+					t.Errorf("expected Match to be true for record %d", m.Index)
+				}
+			}
+			if !slices.Equal(gotIndexes, test.expIndexes) {
+				t.Fatalf("wrong indexes; got %v, expected %v", gotIndexes, test.expIndexes)
+			}
+
+			for i, exp := range test.expSelected {
+				if got := matches[i].Selected; !slices.EqualFunc(got, exp, slog.Attr.Equal) {
+					t.Errorf("match[%d] wrong Selected; got %v, expected %v", i, got, exp)
+				}
+			}
+		})
+	}
+}
+
+func TestParseQueryErrors(t *testing.T) {
+	tests := []struct {
+		query  string
+		expPos int
+	}{
+		{query: "level>=", expPos: 7},
+		{query: "level", expPos: 5},
+		{query: "a = 1 and", expPos: 9},
+		{query: "(a = 1", expPos: 6},
+		{query: "a = 1)", expPos: 5},
+		{query: `msg = "unterminated`, expPos: 6},
+		{query: "a ! 1", expPos: 2},
+		{query: `msg ~ "("`, expPos: 6},
+		{query: "level = LOUD", expPos: 8},
+		{query: "a..b", expPos: 2},
+		{query: "x and a..b", expPos: 8},
+		{query: "a | select b.**", expPos: 13},
+		{query: `x and a\`, expPos: 7},
+		{query: "a | pick b", expPos: 4},
+		{query: "a | select b,", expPos: 13},
+		{query: "and", expPos: 0},
+	}
+
+	for _, test := range tests {
+		t.Run(test.query, func(t *testing.T) {
+			_, err := st.ParseQuery(test.query)
+			if err == nil {
+				t.Fatal("expected an error but got nil")
+			}
+			t.Log(err)
+
+			var queryErr *st.QueryError
+			if !errors.As(err, &queryErr) {
+				t.Fatalf("expected error (%v) to be a QueryError", err)
+			}
+			if queryErr.Pos != test.expPos {
+				t.Errorf("wrong Pos; got %d, expected %d", queryErr.Pos, test.expPos)
+			}
+
+			// The message shows the query, with a caret under the position.
+			lines := strings.Split(err.Error(), "\n")
+			if len(lines) != 3 {
+				t.Fatalf("wrong number of lines in error message; got %d, expected %d", len(lines), 3)
+			}
+			if lines[1] != "\t"+test.query {
+				t.Errorf("wrong query line; got %q, expected %q", lines[1], "\t"+test.query)
+			}
+			if got := strings.Index(lines[2], "^") - 1; got != test.expPos {
+				t.Errorf("wrong caret position; got %d, expected %d", got, test.expPos)
+			}
+		})
+	}
+}
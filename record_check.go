@@ -0,0 +1,91 @@
+package slogtesting
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+	"regexp"
+	"time"
+)
+
+// A RecordCheck is a test on a record. Unlike a [Check], it can look at the
+// fields of the record, such as the level, without going through the builtin
+// attributes. Use [OnAttrs] to run a Check on the attributes of a record.
+type RecordCheck func(slog.Record) error
+
+// OnAttrs makes a RecordCheck which runs c on the attributes of a record, as
+// output by [GetRecordAttrs].
+func OnAttrs(c Check) RecordCheck {
+	return func(r slog.Record) error { return c(GetRecordAttrs(r)) }
+}
+
+// LevelIs makes a RecordCheck for a record with the wanted level.
+func LevelIs(want slog.Level) RecordCheck {
+	return func(r slog.Record) error {
+		if r.Level != want {
+			return fmt.Errorf("wrong level %s, expected %s", r.Level, want)
+		}
+		return nil
+	}
+}
+
+// LevelAtLeast makes a RecordCheck for a record with a level at least as
+// severe as lvl.
+func LevelAtLeast(lvl slog.Level) RecordCheck {
+	return func(r slog.Record) error {
+		if r.Level < lvl {
+			return fmt.Errorf("level %s is less than %s", r.Level, lvl)
+		}
+		return nil
+	}
+}
+
+// MessageIs makes a RecordCheck for a record with the wanted message.
+func MessageIs(want string) RecordCheck {
+	return func(r slog.Record) error {
+		if r.Message != want {
+			return fmt.Errorf("wrong message %q, expected %q", r.Message, want)
+		}
+		return nil
+	}
+}
+
+// MessageMatches makes a RecordCheck for a record whose message matches re.
+func MessageMatches(re *regexp.Regexp) RecordCheck {
+	return func(r slog.Record) error {
+		if !re.MatchString(r.Message) {
+			return fmt.Errorf("message %q does not match %q", r.Message, re)
+		}
+		return nil
+	}
+}
+
+// TimeBetween makes a RecordCheck for a record whose time is from start to
+// end, inclusive.
+func TimeBetween(start, end time.Time) RecordCheck {
+	return func(r slog.Record) error {
+		if r.Time.Before(start) || r.Time.After(end) {
+			return fmt.Errorf("time %s is not between %s and %s",
+				r.Time.Format(time.RFC3339Nano), start.Format(time.RFC3339Nano), end.Format(time.RFC3339Nano),
+			)
+		}
+		return nil
+	}
+}
+
+// HasSourceFunc makes a RecordCheck for a record which was logged from a
+// function. It uses the program counter of the record, so unlike [HasSource],
+// the AddSource field of [slog.HandlerOptions] is not needed. The function
+// name is matched like it is for HasSource.
+func HasSourceFunc(funcName string) RecordCheck {
+	return func(r slog.Record) error {
+		src := r.Source()
+		if src == nil {
+			return errors.New("record has no source")
+		}
+		if !matchFuncName(src.Function, funcName) {
+			return fmt.Errorf("source function %q does not match %q", src.Function, funcName)
+		}
+		return nil
+	}
+}
@@ -0,0 +1,60 @@
+package slogtesting_test
+
+import (
+	"context"
+	"log/slog"
+	"regexp"
+	"testing"
+	"time"
+
+	st "github.com/rafaelespinoza/slogtesting"
+)
+
+func TestRecordCheck(t *testing.T) {
+	before := time.Now()
+	rec := st.NewRecorder(nil)
+	logInfo(rec.Handler(), "hello world", "a", "b")
+	after := time.Now()
+
+	if err := rec.Handler().Handle(context.Background(), slog.NewRecord(time.Now(), slog.LevelWarn, "no pc", 0)); err != nil {
+		t.Fatal(err)
+	}
+
+	records := rec.Records()
+	requireResultLen(t, records, 2)
+
+	tests := []struct {
+		name   string
+		check  st.RecordCheck
+		record slog.Record
+		expErr bool
+	}{
+		{name: "LevelIs", check: st.LevelIs(slog.LevelInfo), record: records[0]},
+		{name: "LevelIs fail", check: st.LevelIs(slog.LevelWarn), record: records[0], expErr: true},
+		{name: "LevelAtLeast", check: st.LevelAtLeast(slog.LevelInfo), record: records[1]},
+		{name: "LevelAtLeast fail", check: st.LevelAtLeast(slog.LevelWarn), record: records[0], expErr: true},
+		{name: "MessageIs", check: st.MessageIs("hello world"), record: records[0]},
+		{name: "MessageIs fail", check: st.MessageIs("hello"), record: records[0], expErr: true},
+		{name: "MessageMatches", check: st.MessageMatches(regexp.MustCompile(`^hello\b`)), record: records[0]},
+		{name: "MessageMatches fail", check: st.MessageMatches(regexp.MustCompile(`^world`)), record: records[0], expErr: true},
+		{name: "TimeBetween", check: st.TimeBetween(before, after), record: records[0]},
+		{name: "TimeBetween fail", check: st.TimeBetween(before.Add(-time.Hour), before.Add(-time.Minute)), record: records[0], expErr: true},
+		{name: "HasSourceFunc", check: st.HasSourceFunc("slogtesting_test.logInfo"), record: records[0]},
+		{name: "HasSourceFunc fail", check: st.HasSourceFunc("TestRecordCheck"), record: records[0], expErr: true},
+		{name: "HasSourceFunc no pc", check: st.HasSourceFunc("logInfo"), record: records[1], expErr: true},
+		{name: "OnAttrs", check: st.OnAttrs(st.HasAttr(slog.String("a", "b"))), record: records[0]},
+		{name: "OnAttrs fail", check: st.OnAttrs(st.HasKey("a")), record: records[1], expErr: true},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			err := test.check(test.record)
+			if test.expErr && err == nil {
+				t.Fatal("expected an error but got nil")
+			} else if !test.expErr && err != nil {
+				t.Fatalf("unexpected error %v", err)
+			}
+			t.Log(err)
+		})
+	}
+}
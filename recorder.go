@@ -0,0 +1,304 @@
+package slogtesting
+
+import (
+	"fmt"
+	"log/slog"
+	"maps"
+	"slices"
+	"sync"
+)
+
+// A Recorder keeps every record processed by its handler in memory. It's meant
+// for longer-lived code under test, such as a server in an integration test,
+// where the records should be inspected between steps without rebuilding the
+// handler. All methods are safe for concurrent use.
+//
+// By default, a Recorder keeps every record. For long-running tests, bound it
+// with the MaxRecords or MaxBytes fields of [RecorderOptions], as passed to
+// [NewRecorderWithOptions]. It then acts as a ring buffer, keeping the newest
+// records and counting evictions.
+type Recorder struct {
+	handler    slog.Handler
+	maxRecords int
+	maxBytes   int
+
+	mtx     sync.Mutex
+	records []Envelope
+	// numAdded counts every record ever added. Records are removed by Reset
+	// and by eviction, so it's needed to know the absolute position of the
+	// records field.
+	numAdded   int
+	numEvicted int
+	size       int // estimated size of records
+	// changed is closed, then set to nil, whenever a record is added. It's
+	// only allocated when something is waiting for a record.
+	changed chan struct{}
+	subs    []*subscription
+
+	shadowMode   ShadowMode
+	shadowCounts map[slog.Level]int
+	shadows      []ShadowRecord
+}
+
+// RecorderOptions is a superset of [AttrHandlerOptions] for use in
+// [NewRecorderWithOptions]. The additional fields are about what the Recorder
+// keeps.
+type RecorderOptions struct {
+	AttrHandlerOptions
+
+	// MaxRecords, if positive, bounds the number of records kept. The oldest
+	// records are evicted first.
+	MaxRecords int
+
+	// MaxBytes, if positive, bounds the estimated size of the records kept.
+	// The estimate is based on the lengths of messages, keys and string
+	// values. The oldest records are evicted first, but the newest record is
+	// always kept.
+	MaxBytes int
+
+	// Shadows decides what to do with calls that the handler rejects because
+	// of the Level field. The default, [ShadowOff], ignores them. See also the
+	// CaptureShadow field of AttrHandlerOptions.
+	Shadows ShadowMode
+}
+
+// NewRecorder creates a Recorder whose handler is built like [NewAttrHandler]
+// using the input opts. If the CaptureRecord field of opts is non-empty, then
+// it's called after the record is stored by the Recorder. The Recorder keeps
+// every record; to bound it, see [NewRecorderWithOptions].
+func NewRecorder(opts *AttrHandlerOptions) *Recorder {
+	var recOpts RecorderOptions
+	if opts != nil {
+		recOpts.AttrHandlerOptions = *opts
+	}
+	return NewRecorderWithOptions(&recOpts)
+}
+
+// NewRecorderWithOptions is like [NewRecorder], but it also takes the options
+// specific to a Recorder.
+func NewRecorderWithOptions(opts *RecorderOptions) *Recorder {
+	var recOpts RecorderOptions
+	if opts != nil {
+		recOpts = *opts
+	}
+	handlerOpts := recOpts.AttrHandlerOptions
+
+	out := &Recorder{
+		maxRecords: recOpts.MaxRecords,
+		maxBytes:   recOpts.MaxBytes,
+		shadowMode: recOpts.Shadows,
+	}
+	if out.shadowMode != ShadowOff {
+		captureShadow := handlerOpts.CaptureShadow
+		handlerOpts.CaptureShadow = func(s ShadowRecord) {
+			out.addShadow(s)
+			if captureShadow != nil {
+				captureShadow(s)
+			}
+		}
+	}
+	handler := newAttrHandler(&handlerOpts)
+	handler.sink = func(e Envelope) error {
+		out.add(e)
+		return out.publish(e.Record)
+	}
+	out.handler = handler
+
+	return out
+}
+
+// Handler returns the [slog.Handler] that writes to the Recorder. Handlers
+// derived from it, via WithAttrs or WithGroup, also write to the Recorder.
+func (r *Recorder) Handler() slog.Handler { return r.handler }
+
+// Records returns a snapshot copy of the records in the order they were
+// captured. Later activity on the Recorder does not affect the output.
+func (r *Recorder) Records() []slog.Record {
+	out, _ := r.recordsAndEvicted()
+	return out
+}
+
+// recordsAndEvicted is like Records, but it also outputs the eviction count as
+// of the same moment.
+func (r *Recorder) recordsAndEvicted() ([]slog.Record, int) {
+	r.mtx.Lock()
+	defer r.mtx.Unlock()
+
+	out := make([]slog.Record, len(r.records))
+	for i, e := range r.records {
+		out[i] = e.Record.Clone()
+	}
+	return out, r.numEvicted
+}
+
+// Envelopes returns a snapshot copy of the records with metadata about their
+// capture, in the order they were captured.
+func (r *Recorder) Envelopes() []Envelope {
+	r.mtx.Lock()
+	defer r.mtx.Unlock()
+
+	out := make([]Envelope, len(r.records))
+	for i, e := range r.records {
+		out[i] = e.clone()
+	}
+	return out
+}
+
+// Provenances returns a copy of the provenance index of each record, in the
+// same order as the output of [Recorder.Records]. Each index is empty unless
+// the TrackProvenance field of [AttrHandlerOptions] is true.
+func (r *Recorder) Provenances() []Provenance {
+	r.mtx.Lock()
+	defer r.mtx.Unlock()
+
+	out := make([]Provenance, len(r.records))
+	for i, e := range r.records {
+		out[i] = e.Provenance
+	}
+	return out
+}
+
+// Len returns the number of records currently held.
+func (r *Recorder) Len() int {
+	r.mtx.Lock()
+	defer r.mtx.Unlock()
+	return len(r.records)
+}
+
+// Last returns a copy of the most recently captured record. The second output
+// is false if there are no records.
+func (r *Recorder) Last() (out slog.Record, ok bool) {
+	r.mtx.Lock()
+	defer r.mtx.Unlock()
+
+	if len(r.records) < 1 {
+		return
+	}
+	out, ok = r.records[len(r.records)-1].Record.Clone(), true
+	return
+}
+
+// Evicted returns the number of records discarded to stay within the bounds
+// set by the MaxRecords and MaxBytes fields of [RecorderOptions]. A non-zero
+// value means that the held records are not the complete evidence.
+func (r *Recorder) Evicted() int {
+	r.mtx.Lock()
+	defer r.mtx.Unlock()
+	return r.numEvicted
+}
+
+// ShadowCounts returns the number of calls rejected by the handler's level,
+// grouped by the level of the call. It's empty unless the Shadows field of
+// [RecorderOptions] is [ShadowCount] or [ShadowKeep].
+func (r *Recorder) ShadowCounts() map[slog.Level]int {
+	r.mtx.Lock()
+	defer r.mtx.Unlock()
+	return maps.Clone(r.shadowCounts)
+}
+
+// Shadows returns a copy of the calls rejected by the handler's level, in the
+// order they happened. It's empty unless the Shadows field of
+// [RecorderOptions] is [ShadowKeep].
+func (r *Recorder) Shadows() []ShadowRecord {
+	r.mtx.Lock()
+	defer r.mtx.Unlock()
+	return slices.Clone(r.shadows)
+}
+
+// Reset discards all records and shadow records held by the Recorder, and
+// resets the eviction count. The handler remains usable.
+func (r *Recorder) Reset() {
+	r.mtx.Lock()
+	defer r.mtx.Unlock()
+
+	clear(r.records)
+	r.records = r.records[:0]
+	r.numEvicted = 0
+	r.size = 0
+	clear(r.shadowCounts)
+	r.shadows = nil
+}
+
+func (r *Recorder) add(e Envelope) {
+	r.mtx.Lock()
+	defer r.mtx.Unlock()
+
+	r.records = append(r.records, e)
+	r.numAdded++
+	if r.maxBytes > 0 {
+		r.size += estimateRecordSize(e.Record)
+	}
+	for r.overLimit() {
+		if r.maxBytes > 0 {
+			r.size -= estimateRecordSize(r.records[0].Record)
+		}
+		// Reslicing from the front keeps eviction cheap. Once the capacity is
+		// used up, append copies the remaining records to a new array, so the
+		// memory stays bounded too.
+		r.records[0] = Envelope{}
+		r.records = r.records[1:]
+		r.numEvicted++
+	}
+	if r.changed != nil {
+		close(r.changed)
+		r.changed = nil
+	}
+}
+
+func (r *Recorder) addShadow(s ShadowRecord) {
+	r.mtx.Lock()
+	defer r.mtx.Unlock()
+
+	if r.shadowCounts == nil {
+		r.shadowCounts = make(map[slog.Level]int)
+	}
+	r.shadowCounts[s.Level]++
+	if r.shadowMode == ShadowKeep {
+		r.shadows = append(r.shadows, s)
+	}
+}
+
+func (r *Recorder) overLimit() bool {
+	if r.maxRecords > 0 && len(r.records) > r.maxRecords {
+		return true
+	}
+	return r.maxBytes > 0 && r.size > r.maxBytes && len(r.records) > 1
+}
+
+// evictionNote describes evictions for use in error messages. The output is
+// empty if there were no evictions.
+func evictionNote(numEvicted int) string {
+	if numEvicted < 1 {
+		return ""
+	}
+	return fmt.Sprintf("; %d earlier records were evicted, so the evidence is truncated", numEvicted)
+}
+
+// estimateRecordSize approximates the memory used by a record. It only needs to
+// be consistent, so that the same record always has the same estimate.
+func estimateRecordSize(r slog.Record) int {
+	out := len(r.Message)
+	r.Attrs(func(a slog.Attr) bool {
+		out += estimateAttrSize(a)
+		return true
+	})
+	return out
+}
+
+func estimateAttrSize(a slog.Attr) int {
+	// Use a nominal size for values whose memory is not readily known.
+	const nominalSize = 8
+
+	out := len(a.Key)
+	switch a.Value.Kind() {
+	case slog.KindString:
+		out += len(a.Value.String())
+	case slog.KindGroup:
+		for _, ga := range a.Value.Group() {
+			out += estimateAttrSize(ga)
+		}
+	default:
+		out += nominalSize
+	}
+	return out
+}
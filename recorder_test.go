@@ -0,0 +1,202 @@
+package slogtesting_test
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	st "github.com/rafaelespinoza/slogtesting"
+)
+
+func TestRecorder(t *testing.T) {
+	t.Run("empty", func(t *testing.T) {
+		rec := st.NewRecorder(nil)
+
+		if got := rec.Len(); got != 0 {
+			t.Errorf("wrong Len; got %d, expected %d", got, 0)
+		}
+		if got := rec.Records(); len(got) != 0 {
+			t.Errorf("wrong number of records; got %d, expected %d", len(got), 0)
+		}
+		if _, ok := rec.Last(); ok {
+			t.Error("expected Last to report no record")
+		}
+	})
+
+	t.Run("records, last, reset", func(t *testing.T) {
+		rec := st.NewRecorder(&st.AttrHandlerOptions{})
+		logger := slog.New(rec.Handler())
+
+		logger.Info("first")
+		logger.With("a", "b").WithGroup("G").Info("second", "c", "d")
+
+		if got := rec.Len(); got != 2 {
+			t.Fatalf("wrong Len; got %d, expected %d", got, 2)
+		}
+		records := rec.Records()
+		requireResultLen(t, records, 2)
+		if records[0].Message != "first" {
+			t.Errorf("wrong Message; got %q, expected %q", records[0].Message, "first")
+		}
+
+		last, ok := rec.Last()
+		if !ok {
+			t.Fatal("expected Last to report a record")
+		}
+		if last.Message != "second" {
+			t.Errorf("wrong Message; got %q, expected %q", last.Message, "second")
+		}
+		check := st.InGroup("G", st.HasAttr(slog.String("c", "d")))
+		if err := check(st.GetRecordAttrs(last)); err != nil {
+			t.Error(err)
+		}
+
+		rec.Reset()
+		if got := rec.Len(); got != 0 {
+			t.Errorf("wrong Len after Reset; got %d, expected %d", got, 0)
+		}
+
+		// The handler is still usable after a Reset.
+		logger.Info("third")
+		if got := rec.Len(); got != 1 {
+			t.Errorf("wrong Len after Reset and logging; got %d, expected %d", got, 1)
+		}
+
+		// Previous outputs are snapshots, unaffected by the Reset.
+		requireResultLen(t, records, 2)
+		if records[1].Message != "second" {
+			t.Errorf("wrong Message; got %q, expected %q", records[1].Message, "second")
+		}
+	})
+
+	t.Run("capture record func", func(t *testing.T) {
+		testErr := errors.New("test")
+		var numCalls int
+		rec := st.NewRecorder(&st.AttrHandlerOptions{
+			CaptureRecord: func(r slog.Record) error {
+				numCalls++
+				return testErr
+			},
+		})
+
+		err := rec.Handler().Handle(t.Context(), slog.NewRecord(time.Now(), slog.LevelInfo, "msg", 0))
+		if !errors.Is(err, testErr) {
+			t.Errorf("wrong error; got %v, expected %v", err, testErr)
+		}
+		if numCalls != 1 {
+			t.Errorf("wrong number of calls to CaptureRecord; got %d, expected %d", numCalls, 1)
+		}
+		if got := rec.Len(); got != 1 {
+			t.Errorf("record should be stored even if CaptureRecord fails; got Len %d", got)
+		}
+	})
+
+	t.Run("concurrent", func(t *testing.T) {
+		const numGoroutines, numRecords = 8, 50
+		rec := st.NewRecorder(nil)
+		logger := slog.New(rec.Handler())
+
+		var wg sync.WaitGroup
+		for range numGoroutines {
+			wg.Go(func() {
+				for range numRecords {
+					logger.Info("msg")
+					_ = rec.Len()
+				}
+			})
+		}
+		wg.Wait()
+
+		if got := rec.Len(); got != numGoroutines*numRecords {
+			t.Errorf("wrong Len; got %d, expected %d", got, numGoroutines*numRecords)
+		}
+	})
+}
+
+func TestRecorderBounded(t *testing.T) {
+	t.Run("max records", func(t *testing.T) {
+		rec := st.NewRecorderWithOptions(&st.RecorderOptions{MaxRecords: 3})
+		logger := slog.New(rec.Handler())
+		for i := range 10 {
+			logger.Info("msg", "i", i)
+		}
+
+		records := rec.Records()
+		requireResultLen(t, records, 3)
+		for i, r := range records {
+			check := st.HasAttr(slog.Int("i", 7+i))
+			if err := check(st.GetRecordAttrs(r)); err != nil {
+				t.Errorf("record[%d] %v", i, err)
+			}
+		}
+		if got := rec.Evicted(); got != 7 {
+			t.Errorf("wrong number of evictions; got %d, expected %d", got, 7)
+		}
+
+		rec.Reset()
+		if got := rec.Evicted(); got != 0 {
+			t.Errorf("wrong number of evictions after Reset; got %d, expected %d", got, 0)
+		}
+	})
+
+	t.Run("max bytes", func(t *testing.T) {
+		rec := st.NewRecorderWithOptions(&st.RecorderOptions{MaxBytes: 200})
+		logger := slog.New(rec.Handler())
+		for range 20 {
+			logger.Info("0123456789", "key", "0123456789")
+		}
+
+		got := rec.Len()
+		if got < 1 || got >= 20 {
+			t.Errorf("wrong number of records; got %d, expected fewer than %d", got, 20)
+		}
+		if rec.Evicted()+got != 20 {
+			t.Errorf("records and evictions should add up; got %d + %d", got, rec.Evicted())
+		}
+
+		// The newest record is always kept, even if it's too big.
+		logger.Info("msg", "key", string(make([]byte, 1000)))
+		requireResultLen(t, rec.Records(), 1)
+	})
+
+	t.Run("with handler options", func(t *testing.T) {
+		rec := st.NewRecorderWithOptions(&st.RecorderOptions{
+			AttrHandlerOptions: st.AttrHandlerOptions{HandlerOptions: slog.HandlerOptions{Level: slog.LevelWarn}},
+			MaxRecords:         1,
+		})
+		logger := slog.New(rec.Handler())
+		logger.Warn("first")
+		logger.Info("not captured")
+		logger.Warn("second")
+
+		records := rec.Records()
+		requireResultLen(t, records, 1)
+		if records[0].Message != "second" {
+			t.Errorf("wrong Message; got %q, expected %q", records[0].Message, "second")
+		}
+		if got := rec.Evicted(); got != 1 {
+			t.Errorf("wrong Evicted; got %d, expected %d", got, 1)
+		}
+	})
+
+	t.Run("wait error", func(t *testing.T) {
+		rec := st.NewRecorderWithOptions(&st.RecorderOptions{MaxRecords: 1})
+		logger := slog.New(rec.Handler())
+		logger.Info("first")
+		logger.Info("second")
+
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+		_, err := rec.WaitFor(ctx, st.HasKey("nope"))
+		if err == nil {
+			t.Fatal("expected an error but got nil")
+		}
+		if !strings.Contains(err.Error(), "1 earlier records were evicted") {
+			t.Errorf("expected error to report evictions; got %v", err)
+		}
+	})
+}
@@ -0,0 +1,154 @@
+package slogtesting
+
+import (
+	"context"
+	"log/slog"
+	"strconv"
+	"sync"
+	"sync/atomic"
+)
+
+type captureKeyCtx struct{}
+
+// WithCaptureKey returns a copy of ctx carrying a capture key, which a
+// [Router] uses to deliver records to a [Recorder].
+func WithCaptureKey(ctx context.Context, key string) context.Context {
+	return context.WithValue(ctx, captureKeyCtx{}, key)
+}
+
+// CaptureKey returns the capture key from ctx, if any.
+func CaptureKey(ctx context.Context) (key string, ok bool) {
+	if ctx == nil {
+		return
+	}
+	key, ok = ctx.Value(captureKeyCtx{}).(string)
+	return
+}
+
+// A Router delivers records to Recorders based on the capture key in the
+// context passed to the Handle method of its handler. It's meant for parallel
+// tests that share 1 logger, such as the default logger, which is process-wide.
+// Install the Router's handler once, for example in TestMain, then each test
+// registers its own Recorder with [Router.Capture] or [Router.Register].
+//
+// Records are only routed when the code under test passes a context along to
+// the logger, as with [slog.InfoContext] or [slog.Logger.Log]. The top-level
+// functions without a context parameter, such as [slog.Info], use
+// [context.Background] so their records are never routed. Records without a
+// registered capture key are passed to the fallback handler, if any.
+type Router struct {
+	fallback slog.Handler
+	numKeys  atomic.Uint64
+
+	mtx    sync.RWMutex
+	routes map[string]*Recorder
+}
+
+// NewRouter creates a Router. Records which cannot be routed are passed to
+// fallback. If fallback is nil, those records are dropped.
+func NewRouter(fallback slog.Handler) *Router {
+	return &Router{
+		fallback: fallback,
+		routes:   make(map[string]*Recorder),
+	}
+}
+
+// Handler returns the [slog.Handler] that routes records.
+func (rt *Router) Handler() slog.Handler {
+	return &routerHandler{router: rt, fallback: rt.fallback}
+}
+
+// Register delivers records whose context has the capture key to rec, until
+// the output function is called. Registering a key again replaces the previous
+// Recorder for that key.
+func (rt *Router) Register(key string, rec *Recorder) (unregister func()) {
+	rt.mtx.Lock()
+	defer rt.mtx.Unlock()
+	rt.routes[key] = rec
+
+	return func() {
+		rt.mtx.Lock()
+		defer rt.mtx.Unlock()
+		if rt.routes[key] == rec {
+			delete(rt.routes, key)
+		}
+	}
+}
+
+// Capture creates a new [Recorder] with opts and registers it with a unique
+// capture key until the end of the test. The output context is derived from
+// ctx and carries the capture key; pass it to the code under test.
+func (rt *Router) Capture(ctx context.Context, tb TB, opts *AttrHandlerOptions) (context.Context, *Recorder) {
+	tb.Helper()
+
+	key := logPrefix + "capture-" + strconv.FormatUint(rt.numKeys.Add(1), 10)
+	rec := NewRecorder(opts)
+	tb.Cleanup(rt.Register(key, rec))
+
+	return WithCaptureKey(ctx, key), rec
+}
+
+func (rt *Router) lookup(ctx context.Context) *Recorder {
+	key, ok := CaptureKey(ctx)
+	if !ok {
+		return nil
+	}
+
+	rt.mtx.RLock()
+	defer rt.mtx.RUnlock()
+	return rt.routes[key]
+}
+
+type routerHandler struct {
+	router   *Router
+	goas     *groupsOrAttrs
+	fallback slog.Handler
+}
+
+func (h *routerHandler) Enabled(ctx context.Context, lvl slog.Level) bool {
+	if rec := h.router.lookup(ctx); rec != nil {
+		return rec.Handler().Enabled(ctx, lvl)
+	}
+	return h.fallback != nil && h.fallback.Enabled(ctx, lvl)
+}
+
+func (h *routerHandler) Handle(ctx context.Context, r slog.Record) error {
+	if rec := h.router.lookup(ctx); rec != nil {
+		// The Recorder is only known at this point, so derive its handler with
+		// the data accumulated so far.
+		target := replayGroupsOrAttrs(rec.Handler(), h.goas)
+		return target.Handle(ctx, r)
+	}
+	if h.fallback != nil {
+		return h.fallback.Handle(ctx, r)
+	}
+	return nil
+}
+
+func (h *routerHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	if len(attrs) == 0 {
+		return h
+	}
+
+	out := *h
+	out.goas = &groupsOrAttrs{Attrs: attrs, Next: h.goas}
+	if h.fallback != nil {
+		out.fallback = h.fallback.WithAttrs(attrs)
+	}
+
+	return &out
+}
+
+func (h *routerHandler) WithGroup(name string) slog.Handler {
+	if name == "" {
+		return h
+	}
+
+	out := *h
+	out.goas = &groupsOrAttrs{Group: name, Next: h.goas}
+	if h.fallback != nil {
+		out.fallback = h.fallback.WithGroup(name)
+	}
+
+	return &out
+}
@@ -0,0 +1,92 @@
+package slogtesting_test
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"testing"
+
+	st "github.com/rafaelespinoza/slogtesting"
+)
+
+func TestRouter(t *testing.T) {
+	fallback := st.NewRecorder(nil)
+	router := st.NewRouter(fallback.Handler())
+
+	prevLogger := slog.Default()
+	t.Cleanup(func() { slog.SetDefault(prevLogger) })
+	slog.SetDefault(slog.New(router.Handler()).With("a", "b"))
+
+	for i := range 4 {
+		t.Run(fmt.Sprintf("parallel %d", i), func(t *testing.T) {
+			t.Parallel()
+
+			ctx, rec := router.Capture(context.Background(), t, &st.AttrHandlerOptions{
+				HandlerOptions: slog.HandlerOptions{Level: slog.LevelDebug},
+			})
+
+			for j := range i + 1 {
+				slog.Default().WithGroup("G").DebugContext(ctx, "msg", "i", i, "j", j)
+			}
+
+			records := rec.Records()
+			requireResultLen(t, records, i+1)
+			for _, r := range records {
+				check := st.InGroup("G", st.HasAttr(slog.Int("i", i)))
+				if err := check(st.GetRecordAttrs(r)); err != nil {
+					t.Error(err)
+				}
+				check = st.HasAttr(slog.String("a", "b"))
+				if err := check(st.GetRecordAttrs(r)); err != nil {
+					t.Error(err)
+				}
+			}
+		})
+	}
+
+	t.Run("fallback", func(t *testing.T) {
+		slog.InfoContext(context.Background(), "unrouted")
+		slog.InfoContext(st.WithCaptureKey(context.Background(), "unregistered"), "unrouted")
+		// The fallback handler has the default level, INFO.
+		slog.DebugContext(context.Background(), "not enabled")
+
+		records := fallback.Records()
+		requireResultLen(t, records, 2)
+		check := st.HasAttr(slog.String("a", "b"))
+		if err := check(st.GetRecordAttrs(records[0])); err != nil {
+			t.Error(err)
+		}
+	})
+
+	t.Run("unregister", func(t *testing.T) {
+		rec := st.NewRecorder(nil)
+		unregister := router.Register("key", rec)
+		ctx := st.WithCaptureKey(context.Background(), "key")
+
+		slog.InfoContext(ctx, "routed")
+		unregister()
+		slog.InfoContext(ctx, "not routed")
+
+		requireResultLen(t, rec.Records(), 1)
+	})
+
+	t.Run("no fallback", func(t *testing.T) {
+		h := st.NewRouter(nil).Handler().WithAttrs([]slog.Attr{slog.String("a", "b")}).WithGroup("G")
+		if h.Enabled(context.Background(), slog.LevelError) {
+			t.Error("expected handler without a route or fallback to be disabled")
+		}
+		if err := h.Handle(context.Background(), slog.Record{}); err != nil {
+			t.Errorf("unexpected error %v", err)
+		}
+	})
+}
+
+func TestCaptureKey(t *testing.T) {
+	if _, ok := st.CaptureKey(context.Background()); ok {
+		t.Error("expected no capture key")
+	}
+	got, ok := st.CaptureKey(st.WithCaptureKey(context.Background(), "key"))
+	if !ok || got != "key" {
+		t.Errorf("wrong capture key; got %q, %t", got, ok)
+	}
+}
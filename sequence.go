@@ -0,0 +1,145 @@
+package slogtesting
+
+import (
+	"fmt"
+	"log/slog"
+	"strings"
+	"time"
+)
+
+// A SequenceCheck is a test on a sequence of records, such as the output of
+// [Recorder.Records]. Errors refer to records by their index in the sequence.
+type SequenceCheck func([]slog.Record) error
+
+// A RecorderCheck is a test on the records held by a [Recorder].
+type RecorderCheck func(*Recorder) error
+
+// OnRecords makes a RecorderCheck which runs c on the records of a Recorder.
+// If the Recorder evicted any records, then a failure says so, because the
+// records are not the complete evidence.
+func OnRecords(c SequenceCheck) RecorderCheck {
+	return func(r *Recorder) error {
+		records, numEvicted := r.recordsAndEvicted()
+		err := c(records)
+		if err != nil && numEvicted > 0 {
+			err = fmt.Errorf("%w%s", err, evictionNote(numEvicted))
+		}
+		return err
+	}
+}
+
+// ExactlyN makes a SequenceCheck for exactly n records passing filter. A nil
+// filter passes each record.
+func ExactlyN(filter RecordCheck, n int) SequenceCheck {
+	return func(records []slog.Record) error {
+		indexes := filterRecords(records, 0, filter)
+		if len(indexes) != n {
+			return fmt.Errorf("got %d records passing the filter, expected %d%s",
+				len(indexes), n, formatIndexedRecords(records, indexes),
+			)
+		}
+		return nil
+	}
+}
+
+// NoRecord makes a SequenceCheck for the absence of records passing filter.
+// A nil filter passes each record.
+func NoRecord(filter RecordCheck) SequenceCheck {
+	return func(records []slog.Record) error {
+		if indexes := filterRecords(records, 0, filter); len(indexes) > 0 {
+			return fmt.Errorf("got %d unexpected records passing the filter%s",
+				len(indexes), formatIndexedRecords(records, indexes),
+			)
+		}
+		return nil
+	}
+}
+
+// EveryRecord makes a SequenceCheck for each record passing check.
+func EveryRecord(check RecordCheck) SequenceCheck {
+	return func(records []slog.Record) error {
+		var sb strings.Builder
+		numFailed := 0
+		for i, r := range records {
+			if err := check(r); err != nil {
+				numFailed++
+				sb.WriteString(fmt.Sprintf("\n[%d] %s\n\t%v", i, formatRecord(r), err))
+			}
+		}
+		if numFailed > 0 {
+			return fmt.Errorf("%d of %d records did not pass the check%s", numFailed, len(records), sb.String())
+		}
+		return nil
+	}
+}
+
+// InOrder makes a SequenceCheck for records passing each of the filters, in
+// the order of the filters. Other records may be in between. Each filter is
+// matched by the earliest record after the one matched by the previous filter.
+// A nil filter passes each record.
+func InOrder(filters ...RecordCheck) SequenceCheck {
+	return func(records []slog.Record) error {
+		var matched []int
+		next := 0
+		for f, filter := range filters {
+			indexes := filterRecords(records, next, filter)
+			if len(indexes) < 1 {
+				return fmt.Errorf("no record at index %d or later passes filter %d; earlier filters passed%s",
+					next, f, formatIndexedRecords(records, matched),
+				)
+			}
+			matched = append(matched, indexes[0])
+			next = indexes[0] + 1
+		}
+		return nil
+	}
+}
+
+// FollowedByWithin makes a SequenceCheck for each record passing first to be
+// followed by a later record passing then, whose time is at most d after it.
+// A nil filter passes each record.
+func FollowedByWithin(first, then RecordCheck, d time.Duration) SequenceCheck {
+	return func(records []slog.Record) error {
+		var missing []int
+		firsts := filterRecords(records, 0, first)
+		for _, i := range firsts {
+			found := false
+			for _, j := range filterRecords(records, i+1, then) {
+				if diff := records[j].Time.Sub(records[i].Time); diff >= 0 && diff <= d {
+					found = true
+					break
+				}
+			}
+			if !found {
+				missing = append(missing, i)
+			}
+		}
+		if len(missing) > 0 {
+			return fmt.Errorf("%d of %d records passing the first filter were not followed within %s by a record passing the other%s",
+				len(missing), len(firsts), d, formatIndexedRecords(records, missing),
+			)
+		}
+		return nil
+	}
+}
+
+// filterRecords outputs the indexes of the records passing filter, starting
+// at the index start.
+func filterRecords(records []slog.Record, start int, filter RecordCheck) (out []int) {
+	for i := start; i < len(records); i++ {
+		if filter == nil || filter(records[i]) == nil {
+			out = append(out, i)
+		}
+	}
+	return
+}
+
+// formatIndexedRecords renders the records at indexes, 1 per line, with each
+// line preceded by a newline.
+func formatIndexedRecords(records []slog.Record, indexes []int) string {
+	var sb strings.Builder
+	for _, i := range indexes {
+		sb.WriteString(fmt.Sprintf("\n[%d] %s", i, formatRecord(records[i])))
+	}
+	return sb.String()
+}
@@ -0,0 +1,96 @@
+package slogtesting_test
+
+import (
+	"log/slog"
+	"strings"
+	"testing"
+	"time"
+
+	st "github.com/rafaelespinoza/slogtesting"
+)
+
+func TestSequenceCheck(t *testing.T) {
+	start := time.Date(2025, 11, 1, 12, 0, 0, 0, time.UTC)
+	rec := st.NewRecorder(&st.AttrHandlerOptions{Clock: st.StepClock(start, time.Second)})
+	logger := slog.New(rec.Handler())
+	logger.Info("connect", "attempt", 1)
+	logger.Warn("retry", "attempt", 2)
+	logger.Info("connect", "attempt", 3)
+	logger.Info("connected")
+	logger.Error("disconnected")
+	records := rec.Records()
+
+	isConnect := st.MessageIs("connect")
+
+	tests := []struct {
+		name   string
+		check  st.SequenceCheck
+		expErr []string // Parts of the error message.
+	}{
+		{name: "ExactlyN", check: st.ExactlyN(isConnect, 2)},
+		{name: "ExactlyN nil filter", check: st.ExactlyN(nil, 5)},
+		{name: "ExactlyN fail", check: st.ExactlyN(isConnect, 1), expErr: []string{"got 2 records", "[0] level=INFO msg=connect attempt=1", "[2] level=INFO"}},
+		{name: "NoRecord", check: st.NoRecord(st.MessageIs("panic"))},
+		{name: "NoRecord fail", check: st.NoRecord(st.LevelAtLeast(slog.LevelWarn)), expErr: []string{"[1] level=WARN msg=retry", "[4] level=ERROR msg=disconnected"}},
+		{name: "EveryRecord", check: st.EveryRecord(st.LevelAtLeast(slog.LevelInfo))},
+		{name: "EveryRecord fail", check: st.EveryRecord(st.OnAttrs(st.HasKey("attempt"))), expErr: []string{"2 of 5 records", "[3] level=INFO msg=connected", "did not find expected key attempt"}},
+		{name: "InOrder", check: st.InOrder(isConnect, st.LevelIs(slog.LevelWarn), st.MessageIs("connected"))},
+		{name: "InOrder fail", check: st.InOrder(st.MessageIs("connected"), isConnect), expErr: []string{"no record at index 4 or later passes filter 1", "[3] level=INFO msg=connected"}},
+		{name: "FollowedByWithin", check: st.FollowedByWithin(isConnect, st.MessageIs("connected"), 3*time.Second)},
+		{name: "FollowedByWithin fail", check: st.FollowedByWithin(isConnect, st.MessageIs("connected"), 2*time.Second), expErr: []string{"1 of 2 records passing the first filter were not followed within 2s", "[0] level=INFO msg=connect attempt=1"}},
+		{name: "FollowedByWithin not later", check: st.FollowedByWithin(st.MessageIs("disconnected"), nil, time.Hour), expErr: []string{"[4] level=ERROR"}},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			err := test.check(records)
+			if len(test.expErr) < 1 {
+				if err != nil {
+					t.Fatalf("unexpected error %v", err)
+				}
+				return
+			}
+
+			if err == nil {
+				t.Fatal("expected an error but got nil")
+			}
+			t.Log(err)
+			for _, want := range test.expErr {
+				if !strings.Contains(err.Error(), want) {
+					t.Errorf("expected error message to contain %q", want)
+				}
+			}
+		})
+	}
+}
+
+func TestOnRecords(t *testing.T) {
+	rec := st.NewRecorderWithOptions(&st.RecorderOptions{MaxRecords: 2})
+	logger := slog.New(rec.Handler())
+	logger.Info("first")
+	logger.Info("second")
+	logger.Info("third")
+
+	if err := st.OnRecords(st.ExactlyN(nil, 2))(rec); err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+
+	err := st.OnRecords(st.ExactlyN(st.MessageIs("first"), 1))(rec)
+	if err == nil {
+		t.Fatal("expected an error but got nil")
+	}
+	t.Log(err)
+	for _, want := range []string{"got 0 records passing the filter", "1 earlier records were evicted"} {
+		if !strings.Contains(err.Error(), want) {
+			t.Errorf("expected error message to contain %q", want)
+		}
+	}
+
+	// Without evictions, there's nothing to note.
+	rec.Reset()
+	logger.Info("fourth")
+	err = st.OnRecords(st.NoRecord(nil))(rec)
+	if err == nil || strings.Contains(err.Error(), "evicted") {
+		t.Errorf("expected an error without an eviction note; got %v", err)
+	}
+}
@@ -0,0 +1,67 @@
+package slogtesting
+
+import (
+	"log/slog"
+	"reflect"
+	"runtime"
+	"strings"
+	"time"
+)
+
+// A ShadowMode decides what a [Recorder] does with calls rejected by its
+// handler's level. See the Shadows field of [RecorderOptions].
+type ShadowMode int
+
+const (
+	// ShadowOff ignores rejected calls.
+	ShadowOff ShadowMode = iota
+	// ShadowCount counts rejected calls by level.
+	ShadowCount
+	// ShadowKeep counts rejected calls by level, and also keeps a
+	// [ShadowRecord] for each one.
+	ShadowKeep
+)
+
+// A ShadowRecord describes a logging call which was rejected because the
+// handler's Enabled method returned false. There's no message or attributes
+// because slog does not build a record for a rejected call.
+type ShadowRecord struct {
+	// Time is when the call was rejected. It's normalized like the time of a
+	// captured record, as set by the Clock, UTC and TruncateTime fields of
+	// [AttrHandlerOptions].
+	Time  time.Time
+	Level slog.Level
+	// Source is the call site. It's nil if the call site is not known.
+	Source *slog.Source
+}
+
+// pkgPath is the import path of this package, used to recognize call frames
+// within this package.
+var pkgPath = reflect.TypeFor[attrHandler]().PkgPath()
+
+// callerSource finds the first call frame outside of the logging machinery,
+// which is the log/slog package, the log package, and the non-test files of
+// this package. The output is nil if there is no such frame.
+func callerSource() *slog.Source {
+	var pcs [32]uintptr
+	n := runtime.Callers(2, pcs[:])
+	frames := runtime.CallersFrames(pcs[:n])
+
+	for {
+		frame, more := frames.Next()
+		if !isLoggingFrame(frame) {
+			return &slog.Source{Function: frame.Function, File: frame.File, Line: frame.Line}
+		}
+		if !more {
+			return nil
+		}
+	}
+}
+
+func isLoggingFrame(frame runtime.Frame) bool {
+	fn := frame.Function
+	if strings.HasPrefix(fn, "log/slog.") || strings.HasPrefix(fn, "log.") {
+		return true
+	}
+	return strings.HasPrefix(fn, pkgPath+".") && !strings.HasSuffix(frame.File, "_test.go")
+}
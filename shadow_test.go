@@ -0,0 +1,139 @@
+package slogtesting_test
+
+import (
+	"context"
+	"log/slog"
+	"strings"
+	"testing"
+	"time"
+
+	st "github.com/rafaelespinoza/slogtesting"
+)
+
+// logDebug is a named function to help test the source of a shadow record.
+func logDebug(logger *slog.Logger, msg string) { logger.Debug(msg) }
+
+func TestRecorderShadows(t *testing.T) {
+	t.Run("keep", func(t *testing.T) {
+		rec := st.NewRecorderWithOptions(&st.RecorderOptions{Shadows: st.ShadowKeep})
+		logger := slog.New(rec.Handler()).With("a", "b")
+
+		logDebug(logger, "debug")
+		logger.Info("info")
+		logger.Log(context.Background(), slog.LevelDebug-4, "trace")
+
+		requireResultLen(t, rec.Records(), 1)
+
+		counts := rec.ShadowCounts()
+		if len(counts) != 2 || counts[slog.LevelDebug] != 1 || counts[slog.LevelDebug-4] != 1 {
+			t.Errorf("wrong shadow counts; got %v", counts)
+		}
+
+		shadows := rec.Shadows()
+		requireResultLen(t, shadows, 2)
+		if shadows[0].Level != slog.LevelDebug {
+			t.Errorf("wrong Level; got %s, expected %s", shadows[0].Level, slog.LevelDebug)
+		}
+		if shadows[0].Time.IsZero() {
+			t.Error("expected non-zero Time")
+		}
+		src := shadows[0].Source
+		if src == nil {
+			t.Fatal("expected non-nil Source")
+		}
+		if !strings.HasSuffix(src.Function, "slogtesting_test.logDebug") || !strings.HasSuffix(src.File, "shadow_test.go") {
+			t.Errorf("wrong Source; got %+v", src)
+		}
+
+		rec.Reset()
+		if got := rec.ShadowCounts(); len(got) != 0 {
+			t.Errorf("expected empty shadow counts after Reset; got %v", got)
+		}
+		requireResultLen(t, rec.Shadows(), 0)
+	})
+
+	t.Run("count", func(t *testing.T) {
+		rec := st.NewRecorderWithOptions(&st.RecorderOptions{
+			AttrHandlerOptions: st.AttrHandlerOptions{HandlerOptions: slog.HandlerOptions{Level: slog.LevelWarn}},
+			Shadows:            st.ShadowCount,
+		})
+		logger := slog.New(rec.Handler())
+		logger.Debug("debug")
+		logger.Info("info")
+		logger.Info("info")
+		logger.Warn("warn")
+
+		counts := rec.ShadowCounts()
+		if len(counts) != 2 || counts[slog.LevelDebug] != 1 || counts[slog.LevelInfo] != 2 {
+			t.Errorf("wrong shadow counts; got %v", counts)
+		}
+		requireResultLen(t, rec.Shadows(), 0)
+	})
+
+	t.Run("off", func(t *testing.T) {
+		rec := st.NewRecorder(nil)
+		slog.New(rec.Handler()).Debug("debug")
+		if got := rec.ShadowCounts(); len(got) != 0 {
+			t.Errorf("expected empty shadow counts; got %v", got)
+		}
+	})
+
+	t.Run("tee mode", func(t *testing.T) {
+		next := st.NewRecorder(&st.AttrHandlerOptions{HandlerOptions: slog.HandlerOptions{Level: slog.LevelDebug}})
+		rec := st.NewRecorderWithOptions(&st.RecorderOptions{
+			AttrHandlerOptions: st.AttrHandlerOptions{Next: next.Handler()},
+			Shadows:            st.ShadowKeep,
+		})
+		logger := slog.New(rec.Handler())
+		logger.Debug("debug")
+		logger.Log(context.Background(), slog.LevelDebug-4, "trace")
+
+		// The debug call is not captured, but Next handles it, so Enabled
+		// returns true and there's no shadow.
+		requireResultLen(t, rec.Records(), 0)
+		requireResultLen(t, next.Records(), 1)
+		counts := rec.ShadowCounts()
+		if len(counts) != 1 || counts[slog.LevelDebug-4] != 1 {
+			t.Errorf("wrong shadow counts; got %v", counts)
+		}
+	})
+
+	t.Run("handler callback", func(t *testing.T) {
+		var got []st.ShadowRecord
+		h := st.NewAttrHandler(&st.AttrHandlerOptions{
+			CaptureShadow: func(s st.ShadowRecord) { got = append(got, s) },
+		})
+		logDebug(slog.New(h), "debug")
+		requireResultLen(t, got, 1)
+		if got[0].Source == nil || !strings.HasSuffix(got[0].Source.Function, "logDebug") {
+			t.Errorf("wrong Source; got %+v", got[0].Source)
+		}
+	})
+
+	t.Run("clock", func(t *testing.T) {
+		start := time.Date(2024, 1, 2, 3, 4, 5, 6, time.FixedZone("X", 3600))
+		rec := st.NewRecorderWithOptions(&st.RecorderOptions{
+			AttrHandlerOptions: st.AttrHandlerOptions{
+				Clock:        st.StepClock(start, time.Second),
+				UTC:          true,
+				TruncateTime: time.Second,
+			},
+			Shadows: st.ShadowKeep,
+		})
+		logger := slog.New(rec.Handler())
+		logger.Debug("debug")
+		logger.Info("info")
+
+		shadows := rec.Shadows()
+		requireResultLen(t, shadows, 1)
+		expected := start.UTC().Truncate(time.Second)
+		if got := shadows[0].Time; !got.Equal(expected) || got.Location() != time.UTC {
+			t.Errorf("wrong shadow time; got %v, expected %v", got, expected)
+		}
+		records := rec.Records()
+		requireResultLen(t, records, 1)
+		if got := records[0].Time; !got.Equal(expected.Add(time.Second)) {
+			t.Errorf("wrong record time; got %v, expected %v", got, expected.Add(time.Second))
+		}
+	})
+}
@@ -13,7 +13,8 @@ import (
 const logPrefix = "slogtesting: "
 
 // CaptureRecords uses the input opts to create a new handler via [NewAttrHandler],
-// runs your test and collects the records processed by the handler.
+// runs your test and collects the records processed by the handler. It's a
+// shorthand for using a [Recorder] once.
 //
 // If opts is non-empty then its [slog.HandlerOptions] may be used to configure
 // the handler, and if its CaptureRecord field is non-empty then it would be
@@ -22,25 +23,14 @@ const logPrefix = "slogtesting: "
 // execute the code to test. This function may also return an error from the
 // input run function. The output records are what was written to the log. See
 // the package doc for an example.
+//
+// Every record is kept until run returns, so this is not suited to tests which
+// log without bounds, such as soak tests. For those, use a Recorder from
+// [NewRecorderWithOptions], which can be bounded and counts evictions.
 func CaptureRecords(opts *AttrHandlerOptions, run func(h slog.Handler) error) (out []slog.Record, err error) {
-	if opts == nil {
-		opts = &AttrHandlerOptions{}
-	}
-
-	captureRecord := func(r slog.Record) (captErr error) {
-		out = append(out, r)
-		if opts.CaptureRecord != nil {
-			captErr = opts.CaptureRecord(r)
-		}
-		return
-	}
-
-	handler := NewAttrHandler(&AttrHandlerOptions{
-		HandlerOptions: opts.HandlerOptions,
-		CaptureRecord:  captureRecord,
-	})
-
-	err = run(handler)
+	recorder := NewRecorder(opts)
+	err = run(recorder.Handler())
+	out = recorder.Records()
 	return
 }
 
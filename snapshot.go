@@ -0,0 +1,258 @@
+package slogtesting
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io/fs"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// These make [Snapshot] rewrite golden files, in addition to the Update field
+// of [SnapshotOptions]. The package does not define the flag; it's only used
+// if the test package defines it, as in:
+//
+//	var update = flag.Bool("update", false, "rewrite golden files")
+const (
+	updateEnvVar   = "SLOGTESTING_UPDATE"
+	updateFlagName = "update"
+)
+
+// SnapshotOptions configures [Snapshot].
+type SnapshotOptions struct {
+	// Name is the name of the golden file, without the ".golden" extension. A
+	// slash in the name is a directory separator. The default is the name of
+	// the test, which requires the TB passed to Snapshot to have a Name method,
+	// as a *testing.T does.
+	Name string
+	// Dir is the directory of the golden files. The default is "testdata".
+	Dir string
+	// Mask lists the paths of attributes whose values vary from run to run,
+	// such as times, source lines or durations. The value of each one is
+	// written as a placeholder, but its kind is kept. Each item is a path
+	// expression, such as "G.elapsed" or "**.elapsed"; see [AtPath] for the
+	// syntax. The path of a group masks the entire group.
+	Mask []string
+	// Update makes Snapshot write the golden file rather than compare to it.
+	// It's also enabled by the environment variable SLOGTESTING_UPDATE, set
+	// to a true value like "1", or by an -update flag defined by the test
+	// package.
+	Update bool
+}
+
+// maskedValue is the placeholder for the value of a masked attribute.
+const maskedValue = "<masked>"
+
+// Snapshot compares records to the contents of a golden file, and marks the
+// test as failed with a line diff if they're different. In update mode, the
+// golden file is written instead; see the Update field of SnapshotOptions. The
+// records are rendered in a stable, indented text format, with 1 line for each
+// attribute stating its key, kind and value. For records captured by this
+// package's handlers, that includes the builtin attributes, such as the time;
+// consider masking them or using the Clock field of [AttrHandlerOptions].
+func Snapshot(tb TB, records []slog.Record, opts *SnapshotOptions) {
+	tb.Helper()
+
+	var sopts SnapshotOptions
+	if opts != nil {
+		sopts = *opts
+	}
+	if sopts.Name == "" {
+		named, ok := tb.(interface{ Name() string })
+		if !ok {
+			tb.Fatalf(logPrefix + "Snapshot needs a Name in its options, or a TB with a Name method")
+			return
+		}
+		sopts.Name = named.Name()
+	}
+	if sopts.Dir == "" {
+		sopts.Dir = "testdata"
+	}
+	filename := filepath.Join(sopts.Dir, filepath.FromSlash(sopts.Name)+".golden")
+
+	mask := make([]attrPath, len(sopts.Mask))
+	for i, expr := range sopts.Mask {
+		var err error
+		if mask[i], _, err = parsePath(expr); err != nil {
+			tb.Fatalf(logPrefix+"invalid Mask: %v", err)
+			return
+		}
+	}
+	got := formatSnapshot(records, mask)
+
+	if sopts.Update || updateRequested() {
+		if err := os.MkdirAll(filepath.Dir(filename), 0o755); err != nil {
+			tb.Fatalf(logPrefix+"could not create directory for golden file: %v", err)
+			return
+		}
+		if err := os.WriteFile(filename, []byte(got), 0o644); err != nil {
+			tb.Fatalf(logPrefix+"could not write golden file: %v", err)
+		}
+		return
+	}
+
+	want, err := os.ReadFile(filename)
+	if errors.Is(err, fs.ErrNotExist) {
+		tb.Errorf(logPrefix+"golden file %s does not exist; run the test with %s=1 to create it", filename, updateEnvVar)
+		return
+	} else if err != nil {
+		tb.Fatalf(logPrefix+"could not read golden file: %v", err)
+		return
+	}
+
+	if got != string(want) {
+		tb.Errorf(logPrefix+"records do not match golden file %s; run the test with %s=1 to rewrite it\n%s",
+			filename, updateEnvVar, diffLines(string(want), got),
+		)
+	}
+}
+
+// updateRequested reports whether the environment variable or the flag asks
+// for golden files to be rewritten. The flag is looked up at call time, after
+// flags are parsed.
+func updateRequested() bool {
+	if on, err := strconv.ParseBool(os.Getenv(updateEnvVar)); err == nil && on {
+		return true
+	}
+	if f := flag.Lookup(updateFlagName); f != nil {
+		on, err := strconv.ParseBool(f.Value.String())
+		return err == nil && on
+	}
+	return false
+}
+
+// formatSnapshot renders records for a golden file.
+func formatSnapshot(records []slog.Record, mask []attrPath) string {
+	var sb strings.Builder
+	for i, r := range records {
+		fmt.Fprintf(&sb, "record %d\n", i)
+		for _, a := range GetRecordAttrs(r) {
+			writeSnapshotAttr(&sb, 1, nil, a, mask)
+		}
+	}
+	return sb.String()
+}
+
+func writeSnapshotAttr(sb *strings.Builder, depth int, groups []string, a slog.Attr, mask []attrPath) {
+	a.Value = a.Value.Resolve()
+	path := appendPath(groups, a.Key)
+	kind := a.Value.Kind()
+
+	sb.WriteString(strings.Repeat("  ", depth) + formatString(a.Key) + " " + kind.String())
+	if slices.ContainsFunc(mask, func(p attrPath) bool { return p.match(path) }) {
+		sb.WriteString(" " + maskedValue + "\n")
+		return
+	}
+	if kind != slog.KindGroup {
+		sb.WriteString(" " + formatSnapshotValue(a.Value) + "\n")
+		return
+	}
+
+	sb.WriteString("\n")
+	for _, ga := range a.Value.Group() {
+		writeSnapshotAttr(sb, depth+1, path, ga, mask)
+	}
+}
+
+// formatSnapshotValue is like formatValue, but strings are always quoted so
+// that they're distinguishable from other kinds. A value of kind Any also has
+// its type, and a source only has the base name of its file so that it's the
+// same on each machine.
+func formatSnapshotValue(v slog.Value) string {
+	switch v.Kind() {
+	case slog.KindString:
+		return strconv.Quote(v.String())
+	case slog.KindAny:
+		var src *slog.Source
+		switch val := v.Any().(type) {
+		case *slog.Source:
+			src = val
+		case slog.Source:
+			src = &val
+		default:
+			return fmt.Sprintf("(%T) %s", val, strconv.Quote(fmt.Sprint(val)))
+		}
+		if src == nil {
+			return "(*slog.Source) <nil>"
+		}
+		return fmt.Sprintf("(%T) %s:%d %s", v.Any(), filepath.Base(src.File), src.Line, src.Function)
+	default:
+		return formatValue(v)
+	}
+}
+
+// diffLines describes the differences between want and got, line by line,
+// based on their longest common subsequence. Missing lines are prefixed with
+// "-", extra lines with "+". Long runs of identical lines are shortened.
+func diffLines(want, got string) string {
+	const context = 2
+
+	a := strings.Split(strings.TrimSuffix(want, "\n"), "\n")
+	b := strings.Split(strings.TrimSuffix(got, "\n"), "\n")
+
+	// lcs[i][j] is the length of the longest common subsequence of a[i:] and b[j:].
+	lcs := make([][]int, len(a)+1)
+	for i := range lcs {
+		lcs[i] = make([]int, len(b)+1)
+	}
+	for i := len(a) - 1; i >= 0; i-- {
+		for j := len(b) - 1; j >= 0; j-- {
+			if a[i] == b[j] {
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			} else {
+				lcs[i][j] = max(lcs[i+1][j], lcs[i][j+1])
+			}
+		}
+	}
+
+	type line struct {
+		op   byte
+		text string
+	}
+	var lines []line
+	i, j := 0, 0
+	for i < len(a) || j < len(b) {
+		switch {
+		case i < len(a) && j < len(b) && a[i] == b[j]:
+			lines = append(lines, line{' ', a[i]})
+			i++
+			j++
+		case i < len(a) && (j == len(b) || lcs[i+1][j] >= lcs[i][j+1]):
+			lines = append(lines, line{'-', a[i]})
+			i++
+		default:
+			lines = append(lines, line{'+', b[j]})
+			j++
+		}
+	}
+
+	// Only keep identical lines which are near a difference.
+	near := func(k int) bool {
+		for d := max(k-context, 0); d <= min(k+context, len(lines)-1); d++ {
+			if lines[d].op != ' ' {
+				return true
+			}
+		}
+		return false
+	}
+
+	var sb strings.Builder
+	skipped := false
+	for k, l := range lines {
+		if l.op == ' ' && !near(k) {
+			if !skipped {
+				sb.WriteString("...\n")
+			}
+			skipped = true
+			continue
+		}
+		skipped = false
+		sb.WriteString(string(l.op) + " " + l.text + "\n")
+	}
+	return sb.String()
+}
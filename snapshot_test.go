@@ -0,0 +1,125 @@
+package slogtesting_test
+
+import (
+	"errors"
+	"flag"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	st "github.com/rafaelespinoza/slogtesting"
+)
+
+// update is defined like a test package would to rewrite its own golden files.
+// The library does not define a flag of the same name, so there's no conflict,
+// and Snapshot also follows it.
+var update = flag.Bool("update", false, "rewrite golden files")
+
+func TestSnapshot(t *testing.T) {
+	captureRecords := func(t *testing.T, b string) []slog.Record {
+		t.Helper()
+
+		rec := st.NewRecorder(&st.AttrHandlerOptions{
+			HandlerOptions: slog.HandlerOptions{AddSource: true},
+			Clock:          st.FixedClock(time.Date(2025, 11, 1, 12, 0, 0, 0, time.UTC)),
+		})
+		logger := slog.New(rec.Handler()).With("a", 1).WithGroup("G")
+		logger.Info("first", "b", b, "elapsed", time.Duration(time.Now().Nanosecond()))
+		logger.Warn("second", slog.Group("H", "c", true, "d", 1.5), "e", errors.New("oops"))
+		return rec.Records()
+	}
+	mask := []string{slog.SourceKey, "**.elapsed"}
+
+	t.Run("golden", func(t *testing.T) {
+		st.Snapshot(t, captureRecords(t, "bravo"), &st.SnapshotOptions{Mask: mask})
+	})
+
+	t.Run("update and compare", func(t *testing.T) {
+		opts := st.SnapshotOptions{Name: "sub/records", Dir: t.TempDir(), Mask: mask}
+		filename := filepath.Join(opts.Dir, "sub", "records.golden")
+
+		var fake st.FakeTB
+		st.Snapshot(&fake, captureRecords(t, "bravo"), &opts)
+		if errs := fake.Errors(); len(errs) != 1 || !strings.Contains(errs[0], "does not exist") {
+			t.Fatalf("expected an error about a missing golden file; got %q", errs)
+		}
+
+		setUpdateFlag(t)
+		fake = st.FakeTB{}
+		st.Snapshot(&fake, captureRecords(t, "bravo"), &opts)
+		if fake.Failed() {
+			t.Fatalf("unexpected errors %q %q", fake.Errors(), fake.Fatals())
+		}
+		if _, err := os.Stat(filename); err != nil {
+			t.Fatal(err)
+		}
+		*update = false
+
+		fake = st.FakeTB{}
+		st.Snapshot(&fake, captureRecords(t, "bravo"), &opts)
+		if fake.Failed() {
+			t.Fatalf("unexpected errors %q %q", fake.Errors(), fake.Fatals())
+		}
+
+		fake = st.FakeTB{}
+		st.Snapshot(&fake, captureRecords(t, "charlie"), &opts)
+		errs := fake.Errors()
+		if len(errs) != 1 {
+			t.Fatalf("expected an error about a mismatch; got %q", errs)
+		}
+		for _, want := range []string{`-     b String "bravo"`, `+     b String "charlie"`} {
+			if !strings.Contains(errs[0], want) {
+				t.Errorf("expected diff to contain %q", want)
+			}
+		}
+		t.Log(errs[0])
+	})
+
+	t.Run("update mode", func(t *testing.T) {
+		tests := []struct {
+			name  string
+			setup func(t *testing.T, opts *st.SnapshotOptions)
+		}{
+			{name: "option", setup: func(t *testing.T, opts *st.SnapshotOptions) { opts.Update = true }},
+			{name: "environment variable", setup: func(t *testing.T, opts *st.SnapshotOptions) { t.Setenv("SLOGTESTING_UPDATE", "1") }},
+			{name: "flag", setup: func(t *testing.T, opts *st.SnapshotOptions) { setUpdateFlag(t) }},
+		}
+
+		for _, test := range tests {
+			t.Run(test.name, func(t *testing.T) {
+				opts := st.SnapshotOptions{Name: "records", Dir: t.TempDir(), Mask: mask}
+				test.setup(t, &opts)
+
+				var fake st.FakeTB
+				st.Snapshot(&fake, captureRecords(t, "bravo"), &opts)
+				if fake.Failed() {
+					t.Fatalf("unexpected errors %q %q", fake.Errors(), fake.Fatals())
+				}
+				if _, err := os.Stat(filepath.Join(opts.Dir, "records.golden")); err != nil {
+					t.Fatal(err)
+				}
+			})
+		}
+	})
+
+	t.Run("name is required without a test name", func(t *testing.T) {
+		var fake st.FakeTB
+		st.Snapshot(&fake, nil, nil)
+		if fatals := fake.Fatals(); len(fatals) != 1 {
+			t.Errorf("wrong number of calls to Fatalf; got %d, expected %d", len(fatals), 1)
+		}
+	})
+}
+
+// setUpdateFlag sets the flag which makes st.Snapshot rewrite golden files,
+// until the end of the test.
+func setUpdateFlag(t *testing.T) {
+	t.Helper()
+
+	prev := *update
+	*update = true
+	t.Cleanup(func() { *update = prev })
+}
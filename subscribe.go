@@ -0,0 +1,195 @@
+package slogtesting
+
+import (
+	"errors"
+	"log/slog"
+	"slices"
+	"sync"
+)
+
+// An OverflowPolicy decides what happens when a record is captured while a
+// subscriber's channel is full. See [Recorder.Subscribe].
+type OverflowPolicy int
+
+const (
+	// OverflowBlock never drops a record. Records which don't fit in the
+	// channel wait in a queue, in order, until the subscriber makes room. The
+	// logging call that produced them does not wait, so a subscriber may log
+	// through the Recorder's handler in reaction to a record. The queue is
+	// unbounded, so a subscriber which stops receiving holds onto records
+	// until the subscription stops.
+	OverflowBlock OverflowPolicy = iota
+	// OverflowDropOldest discards the oldest record in the channel to make
+	// room for the newest record.
+	OverflowDropOldest
+	// OverflowDropNewest discards the newest record.
+	OverflowDropNewest
+	// OverflowError discards the newest record and makes the handler's Handle
+	// method return [ErrSubscriberFull].
+	OverflowError
+)
+
+// ErrSubscriberFull is returned from the Handle method of a Recorder's handler
+// when a subscriber with the policy [OverflowError] has fallen behind.
+var ErrSubscriberFull = errors.New(logPrefix + "subscriber channel is full")
+
+// Subscribe streams records captured after this call to the output channel,
+// which has capacity buffer. The policy decides what happens when the channel
+// is full. Records are sent in the order they're captured, and each one is a
+// separate copy.
+//
+// Call the output function to stop the subscription and close the channel. It's
+// safe to call more than once. With the policy [OverflowBlock], records which
+// are still queued are sent before the channel is closed, so keep receiving
+// until then.
+func (r *Recorder) Subscribe(buffer int, policy OverflowPolicy) (<-chan slog.Record, func()) {
+	sub := &subscription{
+		policy: policy,
+		ch:     make(chan slog.Record, max(buffer, 0)),
+		done:   make(chan struct{}),
+	}
+	if policy == OverflowBlock {
+		sub.pending = make(chan struct{}, 1)
+		go sub.deliver()
+	}
+
+	r.mtx.Lock()
+	r.subs = append(r.subs, sub)
+	r.mtx.Unlock()
+
+	var once sync.Once
+	unsubscribe := func() {
+		once.Do(func() {
+			r.mtx.Lock()
+			r.subs = slices.DeleteFunc(r.subs, func(s *subscription) bool { return s == sub })
+			r.mtx.Unlock()
+			sub.close()
+		})
+	}
+
+	return sub.ch, unsubscribe
+}
+
+// publish passes rec to each subscriber. It's called while holding the
+// handler's lock, so that each subscriber gets records in the order they're
+// captured. So it must not block, and a subscriber with the policy
+// OverflowBlock gets the record via its queue.
+func (r *Recorder) publish(rec slog.Record) error {
+	r.mtx.Lock()
+	subs := slices.Clone(r.subs)
+	r.mtx.Unlock()
+
+	var errs []error
+	for _, sub := range subs {
+		if err := sub.send(rec.Clone()); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+type subscription struct {
+	policy OverflowPolicy
+	ch     chan slog.Record
+	// done is closed to stop the subscription.
+	done chan struct{}
+
+	// mtx serializes sending on, and closing, the channel, except for the
+	// policy OverflowBlock, where only the deliver goroutine sends.
+	mtx    sync.Mutex
+	closed bool
+
+	// These fields are only for the policy OverflowBlock. The queue holds
+	// records which are not yet sent, and pending signals that it has some.
+	queueMtx sync.Mutex
+	queue    []slog.Record
+	pending  chan struct{}
+}
+
+func (s *subscription) send(rec slog.Record) (err error) {
+	if s.policy == OverflowBlock {
+		s.enqueue(rec)
+		return
+	}
+
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+
+	if s.closed {
+		return
+	}
+
+	select {
+	case s.ch <- rec:
+		return
+	default:
+	}
+
+	switch s.policy {
+	case OverflowDropOldest:
+		// Make room at most once. The receiver may take the next record at any
+		// moment, so it's fine if there's nothing to discard. With an unbuffered
+		// channel, there's never room, so the record is dropped.
+		select {
+		case <-s.ch:
+		default:
+		}
+		select {
+		case s.ch <- rec:
+		default:
+		}
+	case OverflowError:
+		err = ErrSubscriberFull
+	}
+	return
+}
+
+func (s *subscription) enqueue(rec slog.Record) {
+	s.queueMtx.Lock()
+	s.queue = append(s.queue, rec)
+	s.queueMtx.Unlock()
+
+	select {
+	case s.pending <- struct{}{}:
+	default: // already signaled
+	}
+}
+
+// deliver sends queued records, in order. Once the subscription stops, it sends
+// what's left in the queue and closes the channel.
+func (s *subscription) deliver() {
+	defer close(s.ch)
+
+	for {
+		var stopping bool
+		select {
+		case <-s.pending:
+		case <-s.done:
+			stopping = true
+		}
+
+		s.queueMtx.Lock()
+		queue := s.queue
+		s.queue = nil
+		s.queueMtx.Unlock()
+
+		for _, rec := range queue {
+			s.ch <- rec
+		}
+		if stopping {
+			return
+		}
+	}
+}
+
+func (s *subscription) close() {
+	close(s.done)
+	if s.policy == OverflowBlock {
+		return // the deliver goroutine closes the channel
+	}
+
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+	s.closed = true
+	close(s.ch)
+}
@@ -0,0 +1,159 @@
+package slogtesting_test
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"slices"
+	"testing"
+	"time"
+
+	st "github.com/rafaelespinoza/slogtesting"
+)
+
+func TestRecorderSubscribe(t *testing.T) {
+	handle := func(t *testing.T, h slog.Handler, msg string) error {
+		t.Helper()
+		return h.Handle(context.Background(), slog.NewRecord(time.Now(), slog.LevelInfo, msg, 0))
+	}
+
+	receiveMessages := func(ch <-chan slog.Record) (out []string) {
+		for r := range ch {
+			out = append(out, r.Message)
+		}
+		return
+	}
+
+	t.Run("stream", func(t *testing.T) {
+		rec := st.NewRecorder(nil)
+		logger := slog.New(rec.Handler())
+		logger.Info("before subscribing")
+
+		ch, unsubscribe := rec.Subscribe(0, st.OverflowBlock)
+		done := make(chan []string)
+		go func() { done <- receiveMessages(ch) }()
+
+		logger.Info("a")
+		logger.WithGroup("G").Info("b", "c", "d")
+		unsubscribe()
+		unsubscribe() // safe to call again
+		logger.Info("after unsubscribing")
+
+		got := <-done
+		if len(got) != 2 || got[0] != "a" || got[1] != "b" {
+			t.Errorf("wrong messages; got %q", got)
+		}
+		requireResultLen(t, rec.Records(), 4)
+	})
+
+	t.Run("drop oldest", func(t *testing.T) {
+		rec := st.NewRecorder(nil)
+		ch, unsubscribe := rec.Subscribe(2, st.OverflowDropOldest)
+		for _, msg := range []string{"a", "b", "c", "d"} {
+			if err := handle(t, rec.Handler(), msg); err != nil {
+				t.Fatal(err)
+			}
+		}
+		unsubscribe()
+
+		got := receiveMessages(ch)
+		if len(got) != 2 || got[0] != "c" || got[1] != "d" {
+			t.Errorf("wrong messages; got %q", got)
+		}
+	})
+
+	t.Run("drop newest", func(t *testing.T) {
+		rec := st.NewRecorder(nil)
+		ch, unsubscribe := rec.Subscribe(2, st.OverflowDropNewest)
+		for _, msg := range []string{"a", "b", "c", "d"} {
+			if err := handle(t, rec.Handler(), msg); err != nil {
+				t.Fatal(err)
+			}
+		}
+		unsubscribe()
+
+		got := receiveMessages(ch)
+		if len(got) != 2 || got[0] != "a" || got[1] != "b" {
+			t.Errorf("wrong messages; got %q", got)
+		}
+	})
+
+	t.Run("error", func(t *testing.T) {
+		rec := st.NewRecorder(nil)
+		ch, unsubscribe := rec.Subscribe(1, st.OverflowError)
+		if err := handle(t, rec.Handler(), "a"); err != nil {
+			t.Fatal(err)
+		}
+		if err := handle(t, rec.Handler(), "b"); !errors.Is(err, st.ErrSubscriberFull) {
+			t.Errorf("wrong error; got %v, expected %v", err, st.ErrSubscriberFull)
+		}
+		unsubscribe()
+
+		got := receiveMessages(ch)
+		if len(got) != 1 || got[0] != "a" {
+			t.Errorf("wrong messages; got %q", got)
+		}
+		// The record is still captured.
+		requireResultLen(t, rec.Records(), 2)
+	})
+
+	t.Run("subscriber logs", func(t *testing.T) {
+		rec := st.NewRecorder(nil)
+		logger := slog.New(rec.Handler())
+		ch, unsubscribe := rec.Subscribe(0, st.OverflowBlock)
+
+		received := make(chan []string)
+		go func() {
+			var got []string
+			for r := range ch {
+				got = append(got, r.Message)
+				if r.Message == "ping" {
+					logger.Info("pong")
+				}
+			}
+			received <- got
+		}()
+
+		done := make(chan struct{})
+		go func() {
+			logger.Info("ping")
+			close(done)
+		}()
+
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		select {
+		case <-done:
+		case <-ctx.Done():
+			t.Fatal("logging was blocked")
+		}
+		if _, err := rec.WaitForN(ctx, 2, nil); err != nil {
+			t.Fatal(err)
+		}
+		unsubscribe()
+
+		got := <-received
+		expected := []string{"ping", "pong"}
+		if !slices.Equal(got, expected) {
+			t.Errorf("wrong messages; got %q, expected %q", got, expected)
+		}
+	})
+
+	t.Run("block does not wait for subscriber", func(t *testing.T) {
+		rec := st.NewRecorder(nil)
+		ch, unsubscribe := rec.Subscribe(0, st.OverflowBlock)
+
+		// Nothing receives yet, so the records are queued.
+		for _, msg := range []string{"a", "b"} {
+			if err := handle(t, rec.Handler(), msg); err != nil {
+				t.Fatal(err)
+			}
+		}
+		unsubscribe()
+
+		got := receiveMessages(ch)
+		if len(got) != 2 || got[0] != "a" || got[1] != "b" {
+			t.Errorf("wrong messages; got %q", got)
+		}
+	})
+}
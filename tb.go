@@ -0,0 +1,112 @@
+package slogtesting
+
+import (
+	"fmt"
+	"runtime"
+	"sync"
+)
+
+// TB is the subset of [testing.TB] used by this package. A *testing.T,
+// *testing.B and *testing.F each satisfy it, as does a [FakeTB]. The package
+// does not otherwise depend upon the testing package.
+type TB interface {
+	Helper()
+	Errorf(format string, args ...any)
+	Fatalf(format string, args ...any)
+	Cleanup(func())
+}
+
+// FakeTB is a TB which records failures instead of failing a test. Use it to
+// test the failure output of a check, an assertion, or your own test helpers.
+// The zero value is ready to use. It's safe to use concurrently.
+//
+// Like a *testing.T, calling Fatalf within [FakeTB.Run] stops the goroutine
+// running the function. Elsewhere, the call is recorded and execution
+// continues, so the caller should return after it.
+type FakeTB struct {
+	mtx      sync.Mutex
+	errors   []string
+	fatals   []string
+	cleanups []func()
+	running  bool
+}
+
+// Helper does nothing. It's there to satisfy the TB interface.
+func (f *FakeTB) Helper() {}
+
+// Errorf records a formatted error message.
+func (f *FakeTB) Errorf(format string, args ...any) {
+	f.mtx.Lock()
+	defer f.mtx.Unlock()
+	f.errors = append(f.errors, fmt.Sprintf(format, args...))
+}
+
+// Fatalf records a formatted fatal message. Within Run, it then stops the
+// calling goroutine.
+func (f *FakeTB) Fatalf(format string, args ...any) {
+	f.mtx.Lock()
+	f.fatals = append(f.fatals, fmt.Sprintf(format, args...))
+	running := f.running
+	f.mtx.Unlock()
+
+	if running {
+		runtime.Goexit()
+	}
+}
+
+// Cleanup registers fn to be called at the end of Run. Functions are called in
+// the reverse order of registration.
+func (f *FakeTB) Cleanup(fn func()) {
+	f.mtx.Lock()
+	defer f.mtx.Unlock()
+	f.cleanups = append(f.cleanups, fn)
+}
+
+// Run calls fn in its own goroutine and waits for it to return, or to be
+// stopped by Fatalf. Then it calls the functions registered with Cleanup. The
+// output is true if, so far, there are no recorded errors or fatal messages.
+func (f *FakeTB) Run(fn func(tb TB)) bool {
+	f.mtx.Lock()
+	prevRunning := f.running
+	f.running = true
+	f.mtx.Unlock()
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		fn(f)
+	}()
+	<-done
+
+	f.mtx.Lock()
+	f.running = prevRunning
+	cleanups := f.cleanups
+	f.cleanups = nil
+	f.mtx.Unlock()
+
+	for i := len(cleanups) - 1; i >= 0; i-- {
+		cleanups[i]()
+	}
+	return !f.Failed()
+}
+
+// Errors outputs the messages recorded by Errorf, in order.
+func (f *FakeTB) Errors() []string {
+	f.mtx.Lock()
+	defer f.mtx.Unlock()
+	return append([]string(nil), f.errors...)
+}
+
+// Fatals outputs the messages recorded by Fatalf, in order.
+func (f *FakeTB) Fatals() []string {
+	f.mtx.Lock()
+	defer f.mtx.Unlock()
+	return append([]string(nil), f.fatals...)
+}
+
+// Failed reports whether Errorf or Fatalf has been called.
+func (f *FakeTB) Failed() bool {
+	f.mtx.Lock()
+	defer f.mtx.Unlock()
+	return len(f.errors) > 0 || len(f.fatals) > 0
+}
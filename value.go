@@ -0,0 +1,178 @@
+package slogtesting
+
+import (
+	"fmt"
+	"log/slog"
+	"math"
+	"reflect"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// A ValueMatcher is a test on the value of an attribute. The error explains why
+// the value does not match. See [HasValue].
+type ValueMatcher func(slog.Value) error
+
+// HasValue makes a Check for the presence of an attribute with a key, whose
+// value passes m.
+// The Check will return an error unless a matching attribute is found in attrs.
+func HasValue(key string, m ValueMatcher) Check {
+	return func(attrs []slog.Attr) (err error) {
+		matchKey := makeKeyMatcher(key)
+		gotMatches, err := collectNMatchingAttrs(attrs, 1, matchKey)
+		if err != nil {
+			err = fmt.Errorf("looking for attr with key %s: %v", key, err)
+			return
+		}
+
+		if err = m(gotMatches[0].Value.Resolve()); err != nil {
+			err = fmt.Errorf("value of attr with key %s: %w", key, err)
+		}
+		return
+	}
+}
+
+// KindIs makes a ValueMatcher for a value of a kind.
+func KindIs(want slog.Kind) ValueMatcher {
+	return func(v slog.Value) error {
+		if got := v.Kind(); got != want {
+			return fmt.Errorf("KindIs(%s): wrong kind %s", want, got)
+		}
+		return nil
+	}
+}
+
+// StringMatches makes a ValueMatcher for a string matching re.
+func StringMatches(re *regexp.Regexp) ValueMatcher {
+	name := fmt.Sprintf("StringMatches(%q)", re)
+	return func(v slog.Value) error {
+		if err := requireKind(name, v, slog.KindString); err != nil {
+			return err
+		}
+		if !re.MatchString(v.String()) {
+			return fmt.Errorf("%s: %q does not match", name, v.String())
+		}
+		return nil
+	}
+}
+
+// StringHasPrefix makes a ValueMatcher for a string starting with prefix.
+func StringHasPrefix(prefix string) ValueMatcher {
+	name := fmt.Sprintf("StringHasPrefix(%q)", prefix)
+	return func(v slog.Value) error {
+		if err := requireKind(name, v, slog.KindString); err != nil {
+			return err
+		}
+		if !strings.HasPrefix(v.String(), prefix) {
+			return fmt.Errorf("%s: %q does not have the prefix", name, v.String())
+		}
+		return nil
+	}
+}
+
+// IntBetween makes a ValueMatcher for an integer from lo to hi, inclusive. The
+// value may be of kind [slog.KindInt64] or [slog.KindUint64].
+func IntBetween(lo, hi int64) ValueMatcher {
+	name := fmt.Sprintf("IntBetween(%d, %d)", lo, hi)
+	return func(v slog.Value) error {
+		switch v.Kind() {
+		case slog.KindInt64:
+			if n := v.Int64(); n < lo || n > hi {
+				return fmt.Errorf("%s: %d is out of range", name, n)
+			}
+		case slog.KindUint64:
+			if n := v.Uint64(); n > math.MaxInt64 || int64(n) < lo || int64(n) > hi {
+				return fmt.Errorf("%s: %d is out of range", name, n)
+			}
+		default:
+			return fmt.Errorf("%s: wrong kind %s, expected %s or %s", name, v.Kind(), slog.KindInt64, slog.KindUint64)
+		}
+		return nil
+	}
+}
+
+// FloatApprox makes a ValueMatcher for a float within epsilon of want.
+func FloatApprox(want, epsilon float64) ValueMatcher {
+	name := fmt.Sprintf("FloatApprox(%v, %v)", want, epsilon)
+	return func(v slog.Value) error {
+		if err := requireKind(name, v, slog.KindFloat64); err != nil {
+			return err
+		}
+		if got := v.Float64(); !(math.Abs(got-want) <= epsilon) {
+			return fmt.Errorf("%s: %v differs by %v", name, got, math.Abs(got-want))
+		}
+		return nil
+	}
+}
+
+// DurationAtMost makes a ValueMatcher for a duration which is at most limit.
+func DurationAtMost(limit time.Duration) ValueMatcher {
+	name := fmt.Sprintf("DurationAtMost(%s)", limit)
+	return func(v slog.Value) error {
+		if err := requireKind(name, v, slog.KindDuration); err != nil {
+			return err
+		}
+		if got := v.Duration(); got > limit {
+			return fmt.Errorf("%s: %s is longer", name, got)
+		}
+		return nil
+	}
+}
+
+// TimeWithin makes a ValueMatcher for a time which is within tolerance of ref,
+// either before or after it.
+func TimeWithin(ref time.Time, tolerance time.Duration) ValueMatcher {
+	name := fmt.Sprintf("TimeWithin(%s, %s)", ref.Format(time.RFC3339Nano), tolerance)
+	return func(v slog.Value) error {
+		if err := requireKind(name, v, slog.KindTime); err != nil {
+			return err
+		}
+		got := v.Time()
+		if diff := got.Sub(ref).Abs(); diff > tolerance {
+			return fmt.Errorf("%s: %s differs by %s", name, got.Format(time.RFC3339Nano), diff)
+		}
+		return nil
+	}
+}
+
+// NonZero makes a ValueMatcher for a value which is not the zero value of its
+// kind. For the kind [slog.KindAny], the value must be non-nil and not the zero
+// value of its type. For a group, there must be at least 1 attribute.
+func NonZero() ValueMatcher {
+	return func(v slog.Value) error {
+		var zero bool
+		switch v.Kind() {
+		case slog.KindAny:
+			val := v.Any()
+			zero = val == nil || reflect.ValueOf(val).IsZero()
+		case slog.KindBool:
+			zero = !v.Bool()
+		case slog.KindDuration:
+			zero = v.Duration() == 0
+		case slog.KindFloat64:
+			zero = v.Float64() == 0
+		case slog.KindInt64:
+			zero = v.Int64() == 0
+		case slog.KindString:
+			zero = v.String() == ""
+		case slog.KindTime:
+			zero = v.Time().IsZero()
+		case slog.KindUint64:
+			zero = v.Uint64() == 0
+		case slog.KindGroup:
+			zero = len(v.Group()) == 0
+		}
+		if zero {
+			return fmt.Errorf("NonZero(): got the zero value of kind %s", v.Kind())
+		}
+		return nil
+	}
+}
+
+func requireKind(name string, v slog.Value, want slog.Kind) error {
+	if got := v.Kind(); got != want {
+		return fmt.Errorf("%s: wrong kind %s, expected %s", name, got, want)
+	}
+	return nil
+}
@@ -0,0 +1,89 @@
+package slogtesting_test
+
+import (
+	"errors"
+	"log/slog"
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+
+	st "github.com/rafaelespinoza/slogtesting"
+)
+
+func TestHasValue(t *testing.T) {
+	now := time.Now()
+
+	attrs := []slog.Attr{
+		slog.String("s", "hello world"),
+		slog.Int("i", 5),
+		slog.Uint64("u", 7),
+		slog.Float64("f", 0.3),
+		slog.Duration("d", 2*time.Second),
+		slog.Time("t", now),
+		slog.Bool("b", false),
+		slog.Any("e", errors.New("oops")),
+		slog.Any("nil", nil),
+		slog.Group("G", slog.Int("i", 1)),
+	}
+
+	tests := []struct {
+		name   string
+		check  st.Check
+		expErr string
+	}{
+		{name: "KindIs", check: st.HasValue("s", st.KindIs(slog.KindString))},
+		{name: "KindIs fail", check: st.HasValue("s", st.KindIs(slog.KindInt64)), expErr: "KindIs(Int64): wrong kind String"},
+		{name: "StringMatches", check: st.HasValue("s", st.StringMatches(regexp.MustCompile(`^hello\s`)))},
+		{name: "StringMatches fail", check: st.HasValue("s", st.StringMatches(regexp.MustCompile(`^world`))), expErr: `"hello world" does not match`},
+		{name: "StringMatches wrong kind", check: st.HasValue("i", st.StringMatches(regexp.MustCompile(`5`))), expErr: "wrong kind Int64, expected String"},
+		{name: "StringHasPrefix", check: st.HasValue("s", st.StringHasPrefix("hello"))},
+		{name: "StringHasPrefix fail", check: st.HasValue("s", st.StringHasPrefix("world")), expErr: "does not have the prefix"},
+		{name: "IntBetween", check: st.HasValue("i", st.IntBetween(5, 10))},
+		{name: "IntBetween uint", check: st.HasValue("u", st.IntBetween(0, 7))},
+		{name: "IntBetween fail", check: st.HasValue("i", st.IntBetween(6, 10)), expErr: "IntBetween(6, 10): 5 is out of range"},
+		{name: "IntBetween wrong kind", check: st.HasValue("f", st.IntBetween(0, 1)), expErr: "wrong kind Float64"},
+		{name: "FloatApprox", check: st.HasValue("f", st.FloatApprox(0.1+0.2, 1e-9))},
+		{name: "FloatApprox fail", check: st.HasValue("f", st.FloatApprox(0.4, 0.05)), expErr: "FloatApprox(0.4, 0.05)"},
+		{name: "DurationAtMost", check: st.HasValue("d", st.DurationAtMost(2*time.Second))},
+		{name: "DurationAtMost fail", check: st.HasValue("d", st.DurationAtMost(time.Second)), expErr: "2s is longer"},
+		{name: "TimeWithin", check: st.HasValue("t", st.TimeWithin(now.Add(time.Millisecond), time.Second))},
+		{name: "TimeWithin fail", check: st.HasValue("t", st.TimeWithin(now.Add(-time.Hour), time.Second)), expErr: "differs by 1h0m0s"},
+		{name: "NonZero", check: st.HasValue("e", st.NonZero())},
+		{name: "NonZero group", check: st.HasValue("G", st.NonZero())},
+		{name: "NonZero fail", check: st.HasValue("b", st.NonZero()), expErr: "zero value of kind Bool"},
+		{name: "NonZero nil", check: st.HasValue("nil", st.NonZero()), expErr: "zero value of kind Any"},
+		{name: "missing key", check: st.HasValue("x", st.NonZero()), expErr: "looking for attr with key x"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			err := test.check(attrs)
+			if test.expErr == "" {
+				if err != nil {
+					t.Fatalf("unexpected error %v", err)
+				}
+				return
+			}
+
+			if err == nil {
+				t.Fatal("expected an error but got nil")
+			}
+			if !strings.Contains(err.Error(), test.expErr) {
+				t.Errorf("expected error message %q to contain %q", err.Error(), test.expErr)
+			}
+			t.Log(err)
+		})
+	}
+
+	t.Run("in group", func(t *testing.T) {
+		err := st.InGroup("G", st.HasValue("i", st.IntBetween(2, 3)))(attrs)
+		if err == nil {
+			t.Fatal("expected an error but got nil")
+		}
+		var errWithPath interface{ GroupPath() []string }
+		if !errors.As(err, &errWithPath) {
+			t.Fatalf("expected error (%v) to have a group path", err)
+		}
+	})
+}
@@ -0,0 +1,129 @@
+package slogtesting
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"strings"
+)
+
+// maxWaitErrorRecords limits how many seen records are listed in an error.
+const maxWaitErrorRecords = 50
+
+// WaitFor blocks until the Recorder has a record whose attributes pass check,
+// or until ctx is done. It's meant for code under test which logs from a
+// background goroutine. Records that were captured before calling WaitFor are
+// also considered. The output is the first passing record. If check is nil,
+// then every record passes.
+//
+// Waiting is driven by the handler's Handle method; there is no polling. Use
+// [context.WithTimeout] or similar to bound the wait. If ctx is done first,
+// the output error lists what was seen and why each record did not pass.
+func (r *Recorder) WaitFor(ctx context.Context, check Check) (out slog.Record, err error) {
+	matches, err := r.WaitForN(ctx, 1, check)
+	if err != nil {
+		return
+	}
+	out = matches[0]
+	return
+}
+
+// WaitForN is like [Recorder.WaitFor], but it blocks until there are n records
+// whose attributes pass filter. If filter is nil, then every record passes. The
+// output is the first n passing records. It's an error for n to be negative.
+// If n is 0, then WaitForN returns right away.
+func (r *Recorder) WaitForN(ctx context.Context, n int, filter Check) (out []slog.Record, err error) {
+	if n < 0 {
+		err = fmt.Errorf("%sWaitForN needs a non-negative number of records, got %d", logPrefix, n)
+		return
+	} else if n == 0 {
+		out = []slog.Record{}
+		return
+	}
+	if filter == nil {
+		filter = func([]slog.Attr) error { return nil }
+	}
+
+	var (
+		next int // absolute position of the next record to examine.
+		seen []seenRecord
+	)
+	for {
+		var changed <-chan struct{}
+		seen, next, changed = r.collectSince(next, seen, filter)
+
+		out = out[:0]
+		for _, s := range seen {
+			if s.err == nil {
+				out = append(out, s.record)
+			}
+		}
+		if len(out) >= n {
+			out = out[:n]
+			return
+		}
+
+		select {
+		case <-changed:
+		case <-ctx.Done():
+			err = &waitError{cause: ctx.Err(), want: n, got: len(out), seen: seen, numEvicted: r.Evicted()}
+			out = nil
+			return
+		}
+	}
+}
+
+type seenRecord struct {
+	record slog.Record
+	err    error
+}
+
+// collectSince evaluates filter on the records added at or after the absolute
+// position, next, and appends the results to seen. It also outputs the next
+// position to examine, and a channel which is closed upon the next addition.
+func (r *Recorder) collectSince(next int, seen []seenRecord, filter Check) ([]seenRecord, int, <-chan struct{}) {
+	r.mtx.Lock()
+	defer r.mtx.Unlock()
+
+	// Records before this position were removed.
+	first := r.numAdded - len(r.records)
+	for i := max(next, first); i < r.numAdded; i++ {
+		rec := r.records[i-first].Record.Clone()
+		seen = append(seen, seenRecord{record: rec, err: filter(GetRecordAttrs(rec))})
+	}
+
+	if r.changed == nil {
+		r.changed = make(chan struct{})
+	}
+	return seen, r.numAdded, r.changed
+}
+
+// waitError describes what was seen while waiting for records.
+type waitError struct {
+	cause      error
+	want, got  int
+	seen       []seenRecord
+	numEvicted int
+}
+
+func (e *waitError) Error() string {
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "%sfound %d of %d wanted records before done waiting: %v", logPrefix, e.got, e.want, e.cause)
+	fmt.Fprintf(&sb, "\nseen %d records%s", len(e.seen), evictionNote(e.numEvicted))
+
+	seen := e.seen
+	if len(seen) > maxWaitErrorRecords {
+		fmt.Fprintf(&sb, ", showing the last %d", maxWaitErrorRecords)
+		seen = seen[len(seen)-maxWaitErrorRecords:]
+	}
+	offset := len(e.seen) - len(seen)
+	for i, s := range seen {
+		fmt.Fprintf(&sb, "\n[%d] %s", offset+i, formatRecord(s.record))
+		if s.err != nil {
+			fmt.Fprintf(&sb, "\n\tdid not pass: %s", strings.ReplaceAll(s.err.Error(), "\n", "\n\t"))
+		}
+	}
+	return sb.String()
+}
+
+func (e *waitError) Unwrap() error { return e.cause }
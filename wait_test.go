@@ -0,0 +1,155 @@
+package slogtesting_test
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"strings"
+	"testing"
+	"time"
+
+	st "github.com/rafaelespinoza/slogtesting"
+)
+
+func TestRecorderWaitFor(t *testing.T) {
+	t.Run("background", func(t *testing.T) {
+		rec := st.NewRecorder(nil)
+		logger := slog.New(rec.Handler())
+
+		done := make(chan struct{})
+		go func() {
+			defer close(done)
+			for i := range 5 {
+				time.Sleep(time.Millisecond)
+				logger.Info("tick", "i", i)
+			}
+		}()
+		t.Cleanup(func() { <-done })
+
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+
+		got, err := rec.WaitFor(ctx, st.HasAttr(slog.Int("i", 3)))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got.Message != "tick" {
+			t.Errorf("wrong Message; got %q, expected %q", got.Message, "tick")
+		}
+	})
+
+	t.Run("already captured", func(t *testing.T) {
+		rec := st.NewRecorder(nil)
+		logger := slog.New(rec.Handler())
+		logger.Info("a", "ok", true)
+		logger.Info("b", "ok", false)
+		logger.Info("c", "ok", true)
+
+		got, err := rec.WaitForN(context.Background(), 2, st.HasAttr(slog.Bool("ok", true)))
+		if err != nil {
+			t.Fatal(err)
+		}
+		requireResultLen(t, got, 2)
+		if got[0].Message != "a" || got[1].Message != "c" {
+			t.Errorf("wrong messages; got %q, %q", got[0].Message, got[1].Message)
+		}
+
+		got, err = rec.WaitForN(context.Background(), 3, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		requireResultLen(t, got, 3)
+	})
+
+	t.Run("timeout", func(t *testing.T) {
+		rec := st.NewRecorder(nil)
+		logger := slog.New(rec.Handler())
+		logger.Info("first", "i", 1)
+		logger.WithGroup("G").Info("second", "i", 2)
+
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+		defer cancel()
+
+		got, err := rec.WaitForN(ctx, 2, st.HasKey("i"))
+		if err == nil {
+			t.Fatal("expected an error but got nil")
+		}
+		if got != nil {
+			t.Errorf("expected nil output; got %v", got)
+		}
+		if !errors.Is(err, context.DeadlineExceeded) {
+			t.Errorf("expected error to wrap %v; got %v", context.DeadlineExceeded, err)
+		}
+		t.Log(err)
+
+		msg := err.Error()
+		for _, want := range []string{"found 1 of 2", "seen 2 records", "msg=first i=1", "msg=second G.i=2", "did not pass"} {
+			if !strings.Contains(msg, want) {
+				t.Errorf("expected error message to contain %q", want)
+			}
+		}
+	})
+
+	t.Run("number of records", func(t *testing.T) {
+		rec := st.NewRecorder(nil)
+		slog.New(rec.Handler()).Info("a")
+
+		tests := []struct {
+			name   string
+			n      int
+			expLen int
+			expErr bool
+		}{
+			{name: "negative", n: -1, expErr: true},
+			{name: "zero", n: 0, expLen: 0},
+			{name: "positive", n: 1, expLen: 1},
+		}
+
+		for _, test := range tests {
+			t.Run(test.name, func(t *testing.T) {
+				// An already-done context shows that nothing blocks.
+				ctx, cancel := context.WithCancel(context.Background())
+				cancel()
+
+				got, err := rec.WaitForN(ctx, test.n, nil)
+				if test.expErr {
+					if err == nil {
+						t.Fatal("expected an error but got nil")
+					}
+					t.Log(err)
+					return
+				}
+				if err != nil {
+					t.Fatalf("unexpected error %v", err)
+				}
+				requireResultLen(t, got, test.expLen)
+			})
+		}
+	})
+
+	t.Run("reset while waiting", func(t *testing.T) {
+		rec := st.NewRecorder(nil)
+		logger := slog.New(rec.Handler())
+		logger.Info("before", "i", 0)
+
+		done := make(chan struct{})
+		go func() {
+			defer close(done)
+			time.Sleep(time.Millisecond)
+			rec.Reset()
+			logger.Info("after", "i", 1)
+		}()
+		t.Cleanup(func() { <-done })
+
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+
+		got, err := rec.WaitFor(ctx, st.HasAttr(slog.Int("i", 1)))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got.Message != "after" {
+			t.Errorf("wrong Message; got %q, expected %q", got.Message, "after")
+		}
+	})
+}